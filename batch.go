@@ -0,0 +1,69 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"sync"
+)
+
+// BatchResult holds the outcome of decoding one item passed to DecodeBatch.
+type BatchResult struct {
+	// Value is the struct returned by newT, populated from the item.
+	Value interface{}
+	// Modified lists the fields that were modified by the item.
+	Modified []string
+	// Err is the error returned when decoding the item, if any.
+	Err error
+}
+
+// DecodeBatch decodes each item in items into a new struct returned by newT, using up to workers goroutines, and
+// returns the results in the same order as items. newT must return a pointer to a new struct of the same type on every
+// call, such as func() interface{} { return &Sample{} }. The fields of the struct are discovered once and shared by all
+// of the workers. If workers is less than 1, the items are decoded one at a time. The error is only returned if the
+// struct type cannot be handled; errors from individual items are reported in their BatchResult.
+func DecodeBatch(items [][]byte, newT func() interface{}, workers int, opts ...Option) ([]BatchResult, error) {
+	d, err := NewDecoder(newT(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+	out := make([]BatchResult, len(items))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				v := newT()
+				modified, err := d.Decode(items[idx], v)
+				out[idx] = BatchResult{Value: v, Modified: modified, Err: err}
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return out, nil
+}
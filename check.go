@@ -0,0 +1,82 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Check verifies that the type of the provided struct can be handled by modtracker, without unmarshaling anything. It
+// reports every problem it finds, such as conflicting JSON keys, double pointers, or field types that can never be
+// populated from JSON. Check is intended to be called in a unit test or at startup for every struct that is unmarshaled
+// with modtracker.
+func Check(s interface{}, opts ...Option) error {
+	o := buildOptions(opts)
+	fm, err := buildJSONFieldMap(s, o)
+	if err != nil {
+		return errorsOf(err)
+	}
+	var el errorList
+	for _, fv := range fm.values {
+		//func fields are only present when WithFuncResolver is used
+		if fv.kind == reflect.Func {
+			continue
+		}
+		//interface fields, and pointers to interfaces, can hold any type when WithInterfaceResolver or RegisterPolymorphic
+		//is used
+		if fv.internalKind == reflect.Interface && (o.interfaceResolver != nil || polymorphicFor(fv.internalType) != nil) {
+			continue
+		}
+		if err := checkFieldType(fv.name, fv.t); err != nil {
+			el = append(el, err)
+		}
+	}
+	if el == nil {
+		return nil
+	}
+	return el
+}
+
+func checkFieldType(name string, t reflect.Type) error {
+	if t.Kind() == reflect.Ptr {
+		if t.Elem().Kind() == reflect.Ptr {
+			return errors.Errorf("Field %s has unsupported type %s: pointers to pointers are not supported", name, t)
+		}
+		t = t.Elem()
+	}
+	if t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Complex64, reflect.Complex128, reflect.UnsafePointer, reflect.Func, reflect.Chan:
+		return errors.Errorf("Field %s has unsupported type %s", name, t)
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			return errors.Errorf("Field %s has unsupported type %s: a concrete type is required to unmarshal into a non-empty interface", name, t)
+		}
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			return errors.Errorf("Field %s has unsupported type %s: map keys must be strings or integers", name, t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+)
+
+// A Decoder populates structs of a single type from JSON and reports the modified fields. It is created once with
+// NewDecoder, which discovers the fields of the struct type and applies the Options, and can then be reused for any
+// number of calls, including concurrent ones.
+type Decoder struct {
+	fm fieldMap
+	o  *options
+}
+
+// NewDecoder creates a Decoder for the type of the provided struct, which must be passed in as a pointer, such as
+// (*Sample)(nil). The behavior of the Decoder can be changed by passing in one or more Options.
+func NewDecoder(s interface{}, opts ...Option) (*Decoder, error) {
+	o := buildOptions(opts)
+	fm, err := buildJSONFieldMap(s, o)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failure during UnmarshalJSON")
+	}
+	return &Decoder{
+		fm: fm,
+		o:  o,
+	}, nil
+}
+
+// WithOptions returns a new Decoder for the same type that adds opts to the Options of d. The new Decoder shares the
+// fields discovered by d, so deriving variants of a Decoder, such as a strict and a lenient one, is cheap. As with
+// Decode, it is an error to pass Options that change which fields are discovered.
+func (d *Decoder) WithOptions(opts ...Option) (*Decoder, error) {
+	o, err := d.options(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{
+		fm: d.fm,
+		o:  o,
+	}, nil
+}
+
+// options returns the Options of d with opts added, checking that the fields named by options such as
+// WithFieldTransform exist, as NewDecoder does.
+func (d *Decoder) options(opts []Option) (*options, error) {
+	o, err := d.o.with(opts)
+	if err != nil || len(opts) == 0 {
+		return o, err
+	}
+	if err := checkFieldNames(d.fm, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Decode populates target, a pointer to a struct of the type the Decoder was created for, or to a struct that embeds
+// that type, with the JSON in data and returns the modified fields, just like an Unmarshaler. Options passed to Decode
+// are added to the Options of the Decoder for this call only. Options that change which fields are discovered, such as
+// WithAliases, WithNestedTracking, and WithFuncResolver, can only be passed to NewDecoder, and are an error when passed
+// to Decode.
+func (d *Decoder) Decode(data []byte, target interface{}, opts ...Option) ([]string, error) {
+	o, err := d.options(opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := unmarshalJSONInner(d.fm, o, data, target)
+	return res.Modified, err
+}
+
+// DecodeInto works like Decode, but appends the modified fields to dst, after truncating it, instead of allocating a
+// new slice, and returns the result. Reusing dst, for example together with the target struct, saves an allocation per
+// call when decoding at a high rate. If an error is returned, so is dst truncated to zero length, unless
+// WithPartialResults is used. The returned slice shares the backing array of dst, so it must not be used once dst is
+// passed to DecodeInto again.
+func (d *Decoder) DecodeInto(dst []string, data []byte, target interface{}, opts ...Option) ([]string, error) {
+	o, err := d.options(opts)
+	if err != nil {
+		return dst[:0], err
+	}
+	ds := &decodeState{o: o}
+	ds.res.Modified = dst
+	res, err := decodeJSON(d.fm, ds, data, target)
+	if err != nil {
+		if ds.o.partialResults && res.Modified != nil {
+			return res.Modified, err
+		}
+		return dst[:0], err
+	}
+	return res.Modified, nil
+}
+
+// DecodeReader reads all of the JSON from r and decodes it into target like Decode.
+func (d *Decoder) DecodeReader(r io.Reader, target interface{}, opts ...Option) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failure reading JSON")
+	}
+	return d.Decode(data, target, opts...)
+}
@@ -0,0 +1,53 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// enums holds the names registered with RegisterEnum, as a map[string]int64 for each reflect.Type.
+var enums sync.Map
+
+// RegisterEnum registers the names of the values of t, an integer type with named constants such as
+// type Status int, so that fields of type t, or of pointer to t, can be populated from a JSON string holding one of the
+// names as well as from a JSON number. It is an error if a JSON string isn't one of the names. Registering t again
+// replaces its names. RegisterEnum is safe to call concurrently with unmarshaling, and panics if t isn't an integer
+// type.
+func RegisterEnum(t reflect.Type, names map[string]int64) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		panic(fmt.Sprintf("modtracker: RegisterEnum called with non-integer type %s", t))
+	}
+	m := make(map[string]int64, len(names))
+	for k, v := range names {
+		m[k] = v
+	}
+	enums.Store(t, m)
+}
+
+// enumNames returns the names registered for t with RegisterEnum, or nil if there are none.
+func enumNames(t reflect.Type) map[string]int64 {
+	if m, ok := enums.Load(t); ok {
+		return m.(map[string]int64)
+	}
+	return nil
+}
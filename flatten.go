@@ -0,0 +1,119 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// FlattenJSON records which values are present in a JSON object without a struct to unmarshal into. It returns a map of
+// the path of each value to the value, and the paths in the order they appear in the JSON. Keys of nested objects are
+// joined with dots and array elements are indexed with brackets, such as inner.address or tags[0]. Dots, opening
+// brackets, and backslashes that are part of a key are escaped with a backslash, so the key "a.b" has the path a\.b
+// while the key "b" nested in the key "a" has the path a.b. Empty objects and arrays are values of their own. Values
+// are converted like encoding/json does for an interface{}: strings to string, numbers to float64, booleans to bool,
+// and null to nil.
+func FlattenJSON(data []byte) (map[string]interface{}, []string, error) {
+	value, vt, _, err := jsonparser.Get(trimBOM(data))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Invalid JSON")
+	}
+	if vt != jsonparser.Object {
+		return nil, nil, errors.Errorf("Invalid JSON, expected an object, got %s", vt)
+	}
+	f := flattener{values: map[string]interface{}{}}
+	if err := f.object(value, ""); err != nil {
+		return nil, nil, err
+	}
+	return f.values, f.paths, nil
+}
+
+var flattenKeyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`)
+
+type flattener struct {
+	values map[string]interface{}
+	paths  []string
+}
+
+func (f *flattener) object(data []byte, prefix string) error {
+	empty := true
+	err := jsonparser.ObjectEach(data, func(key []byte, value []byte, vt jsonparser.ValueType, _ int) error {
+		empty = false
+		return f.value(value, vt, prefix+flattenKeyEscaper.Replace(string(key)))
+	})
+	if err != nil {
+		return errors.Wrap(err, "Invalid JSON")
+	}
+	if empty && prefix != "" {
+		f.set(prefix[:len(prefix)-1], map[string]interface{}{})
+	}
+	return nil
+}
+
+func (f *flattener) value(value []byte, vt jsonparser.ValueType, path string) error {
+	switch vt {
+	case jsonparser.Object:
+		return f.object(value, path+".")
+	case jsonparser.Array:
+		i := 0
+		var inner error
+		_, err := jsonparser.ArrayEach(value, func(value []byte, vt jsonparser.ValueType, _ int, _ error) {
+			if inner == nil {
+				inner = f.value(value, vt, path+"["+strconv.Itoa(i)+"]")
+			}
+			i++
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Invalid JSON at %s", path)
+		}
+		if inner != nil {
+			return inner
+		}
+		if i == 0 {
+			f.set(path, []interface{}{})
+		}
+	case jsonparser.String:
+		s, err := jsonparser.ParseString(value)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid JSON at %s", path)
+		}
+		f.set(path, s)
+	case jsonparser.Number:
+		n, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid JSON at %s", path)
+		}
+		f.set(path, n)
+	case jsonparser.Boolean:
+		f.set(path, string(value) == "true")
+	case jsonparser.Null:
+		f.set(path, nil)
+	default:
+		return errors.Errorf("Invalid JSON at %s", path)
+	}
+	return nil
+}
+
+func (f *flattener) set(path string, v interface{}) {
+	if _, ok := f.values[path]; !ok {
+		f.paths = append(f.paths, path)
+	}
+	f.values[path] = v
+}
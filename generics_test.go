@@ -0,0 +1,84 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package modtracker
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// optional records whether a value, even null, was provided in the JSON.
+type optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+func (o *optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+type wrapper[T any] struct {
+	Value T `json:"value"`
+}
+
+func TestGenericFields(t *testing.T) {
+	type TSample struct {
+		Count   optional[int]           `json:"count"`
+		Name    optional[string]        `json:"name"`
+		Tags    optional[[]string]      `json:"tags"`
+		Enabled *optional[bool]         `json:"enabled"`
+		Score   wrapper[float64]        `json:"score"`
+		Labels  wrapper[[]string]       `json:"labels"`
+		Limits  map[string]wrapper[int] `json:"limits"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	assert.Nil(t, Check((*TSample)(nil)))
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"count": 3, "name": "Bob", "tags": ["a"], "enabled": true, "score": {"value": 1.5}, "labels": {"value": ["x"]}, "limits": {"a": {"value": 2}}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Name", "Tags", "Enabled", "Score", "Labels", "Limits"}, modified)
+	assert.Equal(t, optional[int]{Value: 3, Set: true}, ts.Count)
+	assert.Equal(t, optional[string]{Value: "Bob", Set: true}, ts.Name)
+	assert.Equal(t, optional[[]string]{Value: []string{"a"}, Set: true}, ts.Tags)
+	assert.Equal(t, &optional[bool]{Value: true, Set: true}, ts.Enabled)
+	assert.Equal(t, wrapper[float64]{Value: 1.5}, ts.Score)
+	assert.Equal(t, wrapper[[]string]{Value: []string{"x"}}, ts.Labels)
+	assert.Equal(t, map[string]wrapper[int]{"a": {Value: 2}}, ts.Limits)
+
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"count": null, "enabled": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Enabled"}, modified)
+	assert.Equal(t, optional[int]{Set: true, Null: true}, ts.Count)
+	assert.Nil(t, ts.Enabled)
+
+	_, err = unmarshal([]byte(`{"count": "three"}`), &ts)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,43 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+// Package testtypes holds types used by the modtracker tests that must be defined outside of package modtracker.
+package testtypes
+
+// Customer mixes exported fields with unexported ones that modtracker can't set from another package.
+type Customer struct {
+	ID     string `json:"id"`
+	name   string
+	Email  string `json:"email"`
+	secret string
+	Note   *string
+	audit
+}
+
+// audit is embedded in Customer but isn't exported.
+type audit struct {
+	CreatedBy string `json:"createdBy"`
+}
+
+// Name returns the unexported name of c.
+func (c Customer) Name() string {
+	return c.name
+}
+
+// Secret returns the unexported secret of c.
+func (c Customer) Secret() string {
+	return c.secret
+}
@@ -0,0 +1,95 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+)
+
+// UnmarshalJSON5 works like UnmarshalJSON, but accepts the relaxed JSON often used for files edited by people: the
+// input can contain // and /* */ comments and trailing commas in objects and arrays. Other JSON5 extensions, such as
+// unquoted keys or single-quoted strings, are not supported.
+func UnmarshalJSON5(data []byte, s interface{}) ([]string, error) {
+	data, err := relaxedJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalJSON(data, s)
+}
+
+// BuildJSON5Unmarshaler works like BuildJSONUnmarshaler, but the returned Unmarshaler accepts the relaxed JSON
+// described in UnmarshalJSON5.
+func BuildJSON5Unmarshaler(s interface{}, opts ...Option) (Unmarshaler, error) {
+	unmarshal, err := BuildJSONUnmarshaler(s, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return func(data []byte, s interface{}) ([]string, error) {
+		data, err := relaxedJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshal(data, s)
+	}, nil
+}
+
+// relaxedJSON returns a copy of data with the comments and trailing commas replaced by spaces, so the offsets in
+// errors still match the input.
+func relaxedJSON(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+	comma := -1
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			comma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end == -1 {
+				return nil, errors.Errorf("Invalid JSON, unterminated comment at offset %d", i)
+			}
+			end += i + 4
+			for ; i < end; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			comma = i
+		case c == '}' || c == ']':
+			if comma != -1 {
+				out[comma] = ' '
+			}
+			comma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			comma = -1
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,138 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+)
+
+// checkJSONType verifies that a JSON value can be stored in a value of type t, descending into JSON objects for map
+// types and into JSON arrays for slice and array types. Every mismatch is returned with the path of the offending
+// value, such as Counts[a][b], so errors inside nested maps name the exact value that is wrong. Types with custom
+// unmarshalers and structs are not inspected further.
+func checkJSONType(t reflect.Type, value []byte, vt jsonparser.ValueType, path string) errorList {
+	if vt == jsonparser.Null {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			return nil
+		}
+		return errorList{errors.Errorf("Invalid type in JSON, cannot assign null to field %s", path)}
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pt := reflect.PtrTo(t)
+	if pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType) || t.Kind() == reflect.Interface {
+		return nil
+	}
+	mismatch := func() errorList {
+		return errorList{errors.Errorf("Invalid type in JSON, expected %s for field %s, got %s", t, path, vt)}
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if vt != jsonparser.Number {
+			return mismatch()
+		}
+		i, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil || reflect.Zero(t).OverflowInt(i) {
+			return errorList{errors.Errorf("Invalid value in JSON, cannot assign %s to %s field %s", value, t, path)}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if vt != jsonparser.Number {
+			return mismatch()
+		}
+		u, err := strconv.ParseUint(string(value), 10, 64)
+		if err != nil || reflect.Zero(t).OverflowUint(u) {
+			return errorList{errors.Errorf("Invalid value in JSON, cannot assign %s to %s field %s", value, t, path)}
+		}
+	case reflect.Float32, reflect.Float64:
+		if vt != jsonparser.Number {
+			return mismatch()
+		}
+	case reflect.String:
+		if vt != jsonparser.String {
+			return mismatch()
+		}
+	case reflect.Bool:
+		if vt != jsonparser.Boolean {
+			return mismatch()
+		}
+	case reflect.Struct:
+		if vt != jsonparser.Object {
+			return mismatch()
+		}
+	case reflect.Map:
+		if vt != jsonparser.Object {
+			return mismatch()
+		}
+		var el errorList
+		err := jsonparser.ObjectEach(value, func(key []byte, value []byte, vt jsonparser.ValueType, _ int) error {
+			if err := checkMapKey(t.Key(), string(key), path); err != nil {
+				el = append(el, err)
+				return nil
+			}
+			el = append(el, checkJSONType(t.Elem(), value, vt, path+"["+string(key)+"]")...)
+			return nil
+		})
+		if err != nil {
+			el = append(el, errors.Wrapf(err, "Invalid JSON for field %s", path))
+		}
+		return el
+	case reflect.Slice, reflect.Array:
+		if vt == jsonparser.String && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		if vt != jsonparser.Array {
+			return mismatch()
+		}
+		var el errorList
+		i := 0
+		_, err := jsonparser.ArrayEach(value, func(value []byte, vt jsonparser.ValueType, _ int, _ error) {
+			el = append(el, checkJSONType(t.Elem(), value, vt, path+"["+strconv.Itoa(i)+"]")...)
+			i++
+		})
+		if err != nil {
+			el = append(el, errors.Wrapf(err, "Invalid JSON for field %s", path))
+		}
+		return el
+	}
+	return nil
+}
+
+// checkMapKey verifies that a JSON object key can be converted to a map key of type kt, the way encoding/json does for
+// maps with integer keys.
+func checkMapKey(kt reflect.Type, key string, path string) error {
+	if reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+		return nil
+	}
+	switch kt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || reflect.Zero(kt).OverflowInt(i) {
+			return errors.Errorf("Invalid key in JSON, cannot use %q as %s key for field %s", key, kt, path)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || reflect.Zero(kt).OverflowUint(u) {
+			return errors.Errorf("Invalid key in JSON, cannot use %q as %s key for field %s", key, kt, path)
+		}
+	}
+	return nil
+}
@@ -18,13 +18,20 @@ package modtracker
 
 import (
 	"bytes"
+	"encoding"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/buger/jsonparser"
 	"github.com/pkg/errors"
 	"io"
+	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Modifiable is implemented by struct types that contain a list of their fields that were populated from JSON.
@@ -33,28 +40,61 @@ type Modifiable interface {
 	GetModified() []string
 }
 
+// PostDecoder is implemented by struct types that need to do additional work, such as normalizing values, after they
+// are populated from JSON. PostDecode is called with the modified fields after the JSON was unmarshaled successfully. If
+// it returns an error, the error is returned by the Unmarshaler.
+type PostDecoder interface {
+	PostDecode(modified []string) error
+}
+
 // An Unmarshaler takes in JSON in the first parameter, a pointer to a struct in the second parameter, populates the
 // struct with the JSON and returns the modified fields as a slice of strings. In case of error, the struct might be
-// partially populated. If there is an error, the modified field slice will be nil.
+// partially populated. If there is an error, the modified field slice will be nil, unless WithPartialResults is used.
+// The modified fields are listed in the order their keys appear in the JSON, unless WithModifiedOrder is used.
+//
+// Fields whose keys don't appear in the JSON are never touched, so an Unmarshaler can apply a partial update to a
+// struct loaded from storage. A field whose key does appear is replaced as a whole: a JSON object replaces the struct,
+// map, or pointer in the field, unless WithMergeIntoExisting is used.
 type Unmarshaler func([]byte, interface{}) ([]string, error)
 
-// UnmarshalJSON provides the default implementation of the Unmarshaler type. It will rediscover the fields in the structure
-// each time it is called; to improve performance, use BuildJSONUnmarshaler to create an Unmarshaler instance with the
-// struct fields pre-calculated.
+// UnmarshalJSON provides the default implementation of the Unmarshaler type. The fields in the structure are discovered
+// the first time a struct type is passed in and cached for later calls, so UnmarshalJSON can be used directly without
+// giving up performance. Use BuildJSONUnmarshaler to create an Unmarshaler instance that accepts Options.
 func UnmarshalJSON(data []byte, s interface{}) ([]string, error) {
-	fm, err := buildJSONFieldMap(s)
+	fm, err := cachedJSONFieldMap(s)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failure during UnmarshalJSON")
 	}
 
-	return unmarshalJSONInner(fm, data, s)
+	res, err := unmarshalJSONInner(fm, defaultOptions, data, s)
+	return res.Modified, err
+}
+
+var (
+	defaultOptions = buildOptions(nil)
+	fieldMapCache  sync.Map
+)
+
+// cachedJSONFieldMap returns the field map for the type of s built with the default options, building it and storing
+// it in the cache when it is first requested.
+func cachedJSONFieldMap(s interface{}) (fieldMap, error) {
+	st := reflect.TypeOf(s)
+	if fm, ok := fieldMapCache.Load(st); ok {
+		return fm.(fieldMap), nil
+	}
+	fm, err := buildJSONFieldMap(s, defaultOptions)
+	if err != nil {
+		return fieldMap{}, err
+	}
+	fieldMapCache.Store(st, fm)
+	return fm, nil
 }
 
 // BuildJSONUnmarshaler generates a custom implementation of the Unmarshaler type for the type of the provided struct.
 // The preferred way to use BuildJSONUnmarshaler is to create a package-level variable and assign it in init with a
 // nil instance of the type:
 //
-// 	type Sample struct {
+//	type Sample struct {
 //		FirstName *string
 //		LastName  *string
 //		Age       *int
@@ -85,17 +125,75 @@ func UnmarshalJSON(data []byte, s interface{}) ([]string, error) {
 //		return nil
 //	}
 //
-func BuildJSONUnmarshaler(s interface{}) (func([]byte, interface{}) ([]string, error), error) {
-	fm, err := buildJSONFieldMap(s)
+// The behavior of the returned Unmarshaler can be changed by passing in one or more Options.
+//
+// The returned Unmarshaler populates pointers to structs of the same type as s. It also accepts pointers to structs
+// that embed that type, such as a family of structs sharing a base struct, and populates the embedded struct, so an
+// Unmarshaler built for the base can decode the shared fields of any of them. Any other target is an error.
+func BuildJSONUnmarshaler(s interface{}, opts ...Option) (func([]byte, interface{}) ([]string, error), error) {
+	o := buildOptions(opts)
+	fm, err := buildJSONFieldMap(s, o)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failure during UnmarshalJSON")
 	}
 
 	return func(data []byte, s interface{}) ([]string, error) {
-		return unmarshalJSONInner(fm, data, s)
+		res, err := unmarshalJSONInner(fm, o, data, s)
+		return res.Modified, err
 	}, nil
 }
 
+// BuildJSONUnmarshalerForType works like BuildJSONUnmarshaler, but takes in the reflect.Type of the struct instead of a
+// nil instance of it. Both the struct type and the pointer to struct type are accepted. This is useful when the type is
+// only known at runtime, such as when it comes from a registry.
+func BuildJSONUnmarshalerForType(t reflect.Type, opts ...Option) (Unmarshaler, error) {
+	if t == nil {
+		return nil, errors.New("Failure during UnmarshalJSON: type is nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	o := buildOptions(opts)
+	fm, err := buildJSONFieldMapForType(t, o)
+	if err == nil {
+		err = checkFieldNames(fm, o)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Failure during UnmarshalJSON")
+	}
+
+	return func(data []byte, s interface{}) ([]string, error) {
+		res, err := unmarshalJSONInner(fm, o, data, s)
+		return res.Modified, err
+	}, nil
+}
+
+// BuildAll builds an Unmarshaler with the default Options for each of the provided nil pointers to structs, such as
+// (*Sample)(nil), and returns them keyed by the struct type. The fields of each struct are also stored in the cache used
+// by UnmarshalJSON. Every struct is checked, and the problems with all of them are returned together, so BuildAll can
+// be called at startup with every struct a service unmarshals.
+func BuildAll(ptrs ...interface{}) (map[reflect.Type]Unmarshaler, error) {
+	out := make(map[reflect.Type]Unmarshaler, len(ptrs))
+	var el errorList
+	for _, p := range ptrs {
+		fm, err := buildJSONFieldMap(p, defaultOptions)
+		if err != nil {
+			el = append(el, errorsOf(wrapErrors(err, "Failure during UnmarshalJSON of %T", p))...)
+			continue
+		}
+		st := reflect.TypeOf(p)
+		fieldMapCache.Store(st, fm)
+		out[st.Elem()] = func(data []byte, s interface{}) ([]string, error) {
+			res, err := unmarshalJSONInner(fm, defaultOptions, data, s)
+			return res.Modified, err
+		}
+	}
+	if el != nil {
+		return nil, el
+	}
+	return out, nil
+}
+
 type errorList []error
 
 func (el errorList) innerErr(verb rune, plusFlag bool) string {
@@ -125,6 +223,35 @@ func (el errorList) Format(s fmt.State, verb rune) {
 	io.WriteString(s, msg)
 }
 
+// asError returns nil if el is empty, the only error in el if there is just one, or el itself.
+func (el errorList) asError() error {
+	switch len(el) {
+	case 0:
+		return nil
+	case 1:
+		return el[0]
+	}
+	return el
+}
+
+// errorsOf returns the errors in err, which is either a single error or an errorList.
+func errorsOf(err error) errorList {
+	if el, ok := err.(errorList); ok {
+		return el
+	}
+	return errorList{err}
+}
+
+// wrapErrors wraps each of the errors in err, which is either a single error or an errorList, with the message.
+func wrapErrors(err error, format string, args ...interface{}) error {
+	el := errorsOf(err)
+	out := make(errorList, len(el))
+	for i, v := range el {
+		out[i] = errors.Wrapf(v, format, args...)
+	}
+	return out.asError()
+}
+
 func validateType(nt reflect.Type, typeKind reflect.Kind, n string, validKind reflect.Kind, jsonType string) error {
 	if typeKind != validKind {
 		return errors.Errorf("Invalid type in JSON, expected %s for field %s, got %s", nt, n, jsonType)
@@ -132,149 +259,1478 @@ func validateType(nt reflect.Type, typeKind reflect.Kind, n string, validKind re
 	return nil
 }
 
+// acceptsContainer reports whether a field of kind k can be populated by json.Unmarshal from a JSON object or array.
+func acceptsContainer(k reflect.Kind, vt jsonparser.ValueType) bool {
+	switch k {
+	case reflect.Interface:
+		return true
+	case reflect.Struct, reflect.Map:
+		return vt == jsonparser.Object
+	case reflect.Slice, reflect.Array:
+		return vt == jsonparser.Array
+	}
+	return false
+}
+
 var (
-	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	unmarshalerType       = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	urlType               = reflect.TypeOf(url.URL{})
+	timeType              = reflect.TypeOf(time.Time{})
+	extrasType            = reflect.TypeOf(map[string]json.RawMessage{})
+	numberType            = reflect.TypeOf(json.Number(""))
+	bytesType             = reflect.TypeOf([]byte(nil))
 )
 
-func unmarshalJSONInner(fm fieldMap, data []byte, s interface{}) ([]string, error) {
-	modified := make([]string, 0, len(fm.names))
-	var el errorList
-	se := reflect.ValueOf(s).Elem()
-	jsonparser.EachKey(data, func(idx int, value []byte, vt jsonparser.ValueType, err error) {
-		var fv reflect.Value
-		fValue := fm.values[idx]
-		t := fValue.t
-		n := fValue.name
-		fv = reflect.New(fValue.internalType)
-		switch vt {
-		case jsonparser.String:
-			if fValue.unmarshaler {
-				b := make([]byte, len(value)+2)
-				b[0] = 34
-				b[len(b)-1] = 34
-				copy(b[1:], value)
-				err = json.Unmarshal(b, fv.Interface())
-				if err != nil {
-					el = append(el, errors.Wrap(err, "JSON unmarshaling"))
-					return
+type decodeState struct {
+	o         *options
+	res       Result
+	el        errorList
+	present   int                    //number of top-level fields found in the JSON
+	stopped   bool                   //WithMaxFields limit was exceeded
+	processed int                    //number of JSON keys matched to fields so far, for WithProgress
+	snapshot  map[string]interface{} //values of the modified fields before decoding, for DecodeWithSnapshot
+	detailed  bool                   //fill in the lists of a Result other than Modified, for a DetailedUnmarshaler
+	input     []byte                 //JSON as passed in, for a top-level field tagged modtracker:"raw"
+	original  []byte                 //input before plusSigns, for WithLenientNumberSyntax
+	rewritten []byte                 //input after plusSigns, which is what is parsed
+	locs      map[string]location    //location of each recorded field, for WithModifiedOrder and WithJSONPointerPaths
+	loc       location               //location of the field being decoded
+	frozen    bool                   //fields being decoded aren't reported, so overLimit uses counted
+	counted   int                    //number of modified fields when decodeState was frozen
+	concrete  *sync.Map              //field maps of the concrete types of polymorphic fields, from the top field map
+}
+
+// resultMark holds the lengths of the lists in a Result, so the fields recorded after it can be dropped again.
+type resultMark struct {
+	modified, nulled, cleared, empty, changes, setNonNull int
+	frozen                                                bool
+	counted                                               int
+}
+
+// mark returns the current lengths of the lists in d.res.
+func (d *decodeState) mark() resultMark {
+	return resultMark{
+		modified:   len(d.res.Modified),
+		nulled:     len(d.res.Nulled),
+		cleared:    len(d.res.Cleared),
+		empty:      len(d.res.Empty),
+		changes:    len(d.res.Changes),
+		setNonNull: len(d.res.SetNonNull),
+		frozen:     d.frozen,
+		counted:    d.counted,
+	}
+}
+
+// freeze stops the fields recorded from now on from counting against WithMaxFields, until reset is called.
+func (d *decodeState) freeze() {
+	if !d.frozen {
+		d.frozen, d.counted = true, len(d.res.Modified)
+	}
+}
+
+// reset drops the fields recorded since m was returned by mark.
+func (d *decodeState) reset(m resultMark) {
+	d.res.Modified = d.res.Modified[:m.modified]
+	d.res.Nulled = d.res.Nulled[:m.nulled]
+	d.res.Cleared = d.res.Cleared[:m.cleared]
+	d.res.Empty = d.res.Empty[:m.empty]
+	d.res.Changes = d.res.Changes[:m.changes]
+	d.res.SetNonNull = d.res.SetNonNull[:m.setNonNull]
+	d.frozen, d.counted = m.frozen, m.counted
+}
+
+// source returns the bytes of the input that v, a part of the JSON being parsed, was read from. With
+// WithLenientNumberSyntax, the JSON being parsed is a copy of the input without the plus signs in front of numbers,
+// and source returns the bytes as they were sent instead, including such a plus sign.
+func (d *decodeState) source(v []byte) []byte {
+	if d.rewritten == nil {
+		return v
+	}
+	//v is part of d.rewritten, and the offsets in d.rewritten match those in d.original
+	p, base := reflect.ValueOf(v).Pointer(), reflect.ValueOf(d.rewritten).Pointer()
+	if p < base || int(p-base)+len(v) > len(d.original) {
+		return v
+	}
+	i := int(p - base)
+	start := i
+	if start > 0 && d.original[start-1] == '+' {
+		start--
+	}
+	return d.original[start : i+len(v)]
+}
+
+// overLimit reports whether more fields were modified than allowed by WithMaxFields. The first time the limit is
+// exceeded, an error is recorded and decoding stops.
+func (d *decodeState) overLimit() bool {
+	if d.stopped {
+		return true
+	}
+	count := len(d.res.Modified)
+	if d.frozen {
+		count = d.counted
+	}
+	if d.o.maxFields > 0 && count > d.o.maxFields {
+		d.stopped = true
+		d.el = append(d.el, errors.Errorf("JSON sets more than the maximum of %d fields", d.o.maxFields))
+	}
+	return d.stopped
+}
+
+func unmarshalJSONInner(fm fieldMap, o *options, data []byte, s interface{}) (Result, error) {
+	return decodeJSON(fm, &decodeState{o: o}, data, s)
+}
+
+// decodeJSON populates s from data using the options and the snapshot map, if any, in d. If d.res.Modified is set, the
+// modified fields are appended to it after truncating it, instead of to a new slice.
+func decodeJSON(fm fieldMap, d *decodeState, data []byte, s interface{}) (Result, error) {
+	o := d.o
+	modified := d.res.Modified[:0]
+	if modified == nil {
+		modified = make([]string, 0, len(fm.values))
+	}
+	d.res = Result{Modified: modified}
+	d.concrete = fm.concrete
+	if o.modifiedOrder == DeclarationOrder || o.jsonPointerPaths {
+		d.locs = map[string]location{}
+	}
+	d.input = data
+	data = trimBOM(data)
+	if o.lenientNumbers {
+		d.original, d.rewritten = data, plusSigns(data)
+		data = d.rewritten
+	}
+	if o.stats != nil {
+		start := time.Now()
+		defer func() {
+			o.stats(Stats{
+				BytesIn:          len(data),
+				FieldsPresent:    d.present,
+				FieldsRegistered: len(fm.values),
+				Duration:         time.Since(start),
+			})
+		}()
+	}
+	if o.rejectTrailingData {
+		if err := checkTrailingData(data); err != nil {
+			return Result{}, errorList{err}
+		}
+	}
+	if len(o.rootPath) > 0 {
+		value, vt, _, err := jsonparser.Get(data, o.rootPath...)
+		if err != nil || vt != jsonparser.Object {
+			return Result{}, errorList{errors.Errorf("Invalid JSON, expected an object at %s", strings.Join(o.rootPath, "."))}
+		}
+		data = value
+	}
+	se, err := fm.target(s)
+	if err != nil {
+		return Result{}, err
+	}
+	d.object(&fm, data, se, "")
+	d.overLimit()
+	if o.progress != nil && (d.processed == 0 || d.processed%progressInterval != 0) {
+		o.progress(d.processed)
+	}
+	if o.modifiedOrder == DeclarationOrder {
+		sortDeclarationOrder(d.locs, d.res.Modified, d.res.Nulled, d.res.Cleared, d.res.Empty, d.res.SetNonNull)
+		sort.SliceStable(d.res.Changes, func(i, j int) bool {
+			return lessRank(d.locs[d.res.Changes[i].Field].rank, d.locs[d.res.Changes[j].Field].rank)
+		})
+	}
+	if o.jsonPointerPaths {
+		convertJSONPointers(d.locs, d.res.Modified, d.res.Nulled, d.res.Cleared, d.res.Empty, d.res.SetNonNull)
+		for i, c := range d.res.Changes {
+			d.res.Changes[i].Field = d.locs[c.Field].pointer
+		}
+	}
+
+	if d.el != nil {
+		if o.partialResults {
+			return d.res, d.el
+		}
+		return Result{}, d.el
+	}
+	if pd, ok := s.(PostDecoder); ok {
+		if err := pd.PostDecode(d.res.Modified); err != nil {
+			return Result{}, err
+		}
+	}
+	return d.res, nil
+}
+
+// isEmptyObject reports whether value, a JSON object, has no keys.
+func isEmptyObject(value []byte) bool {
+	return len(bytes.TrimSpace(value[1:len(value)-1])) == 0
+}
+
+// utf8BOM is the byte order mark that some producers put at the start of UTF-8 text.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// trimBOM removes a UTF-8 byte order mark from the start of data. White space around the JSON value is already
+// tolerated by jsonparser.
+func trimBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, utf8BOM)
+}
+
+// plusSigns returns data with the plus signs in front of numbers, which JSON doesn't allow, replaced with spaces, for
+// WithLenientNumberSyntax. Plus signs in strings and in the exponents of numbers are left alone. data is only copied
+// if it contains a plus sign. Since only plus signs are replaced, the offsets in the copy match those in data.
+func plusSigns(data []byte) []byte {
+	if bytes.IndexByte(data, '+') == -1 {
+		return data
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	//value is true where a JSON value can start
+	value := true
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
 				}
+			}
+			value = false
+		case c == '+' && value && i+1 < len(out) && out[i+1] >= '0' && out[i+1] <= '9':
+			out[i] = ' '
+		case c == ':' || c == ',' || c == '[':
+			value = true
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			value = false
+		}
+	}
+	return out
+}
+
+// checkTrailingData returns an error if anything but whitespace follows the first JSON value in data.
+func checkTrailingData(data []byte) error {
+	_, _, end, err := jsonparser.Get(data)
+	if err != nil {
+		return errors.Wrap(err, "Invalid JSON")
+	}
+	if rest := bytes.TrimLeft(data[end:], " \t\r\n"); len(rest) > 0 {
+		return errors.Errorf("Invalid JSON, unexpected data after the top-level value at offset %d", len(data)-len(rest))
+	}
+	return nil
+}
+
+// object populates the struct in se from the JSON object in data. The names of the modified fields are recorded with
+// the provided prefix.
+func (d *decodeState) object(fm *fieldMap, data []byte, se reflect.Value, prefix string) {
+	//set tracks the fields already populated, so a field whose key appears more than once is only recorded once. Most
+	//structs are small enough for it to fit on the stack
+	var setBuf [32]bool
+	set := setBuf[:0]
+	if len(fm.values) <= len(setBuf) {
+		set = setBuf[:len(fm.values)]
+	} else {
+		set = make([]bool, len(fm.values))
+	}
+	var found []bool
+	if fm.required || len(fm.oneOf) > 0 {
+		found = make([]bool, len(fm.values))
+	}
+	before := len(d.res.Modified)
+	//handle populates the field at idx in fm.values
+	handle := func(idx int, value []byte, vt jsonparser.ValueType) {
+		if d.overLimit() {
+			return
+		}
+		d.processed++
+		if d.o.progress != nil && d.processed%progressInterval == 0 {
+			d.o.progress(d.processed)
+		}
+		if found != nil {
+			found[idx] = true
+		}
+		if name := fm.values[idx].name; prefix == "" && d.o.onlyFields != nil && !d.o.onlyFields[name] {
+			if d.o.rejectOtherFields {
+				d.fail(name, errors.Errorf("Field %s may not be set", name))
+			}
+			return
+		}
+		first := !set[idx]
+		if first && prefix == "" {
+			d.present++
+		}
+		if fm.values[idx].deprecated && d.o.deprecationHandler != nil {
+			d.o.deprecationHandler(prefix + fm.values[idx].name)
+		}
+		modified := len(d.res.Modified)
+		parent := d.loc
+		if d.locs != nil {
+			d.loc = d.locate(prefix+fm.values[idx].name, idx, fm.values[idx].key)
+		}
+		ok := d.field(&fm.values[idx], value, vt, se, prefix, first)
+		d.loc = parent
+		if ok {
+			set[idx] = true
+		}
+		if d.detailed && ok && first && vt == jsonparser.Object && isEmptyObject(value) {
+			d.res.Empty = append(d.res.Empty, prefix+fm.values[idx].name)
+		}
+		if d.detailed && len(d.res.Modified) > modified && d.res.Modified[len(d.res.Modified)-1] == prefix+fm.values[idx].name {
+			d.change(prefix+fm.values[idx].name, value, vt)
+		}
+	}
+	var counts []int
+	if d.o.duplicateKeys != nil {
+		counts = make([]int, len(fm.values))
+	}
+	//a struct without any fields to populate is left untouched, whatever the JSON contains. Otherwise every key in the
+	//object is looked at, since jsonparser.EachKey only reports the first of several identical keys, while the last of
+	//them populates the field, like in encoding/json
+	if len(fm.names) > 0 {
+		err := jsonparser.ObjectEach(data, func(key []byte, value []byte, vt jsonparser.ValueType, _ int) error {
+			var idx int
+			var folded, ok bool
+			if fm.normalizer == nil && fm.ciKeys == nil {
+				//converting the key in the map index expression doesn't allocate
+				idx, ok = fm.byKey[string(key)]
 			} else {
-				err := validateType(fValue.internalType, fValue.internalKind, n, reflect.String, "String")
-				if err != nil {
-					el = append(el, err)
-					return
-				}
-				s, _ := jsonparser.ParseString(value)
-				fv.Elem().SetString(s)
+				idx, folded, ok = fm.match(fm.normalize(key))
 			}
-		case jsonparser.Number:
-			switch {
-			case fValue.intType:
-				i, _ := jsonparser.ParseInt(value)
+			if !ok {
+				return nil
+			}
+			if counts != nil {
+				counts[idx]++
+			}
+			//a key that only matches when ignoring case doesn't override a key that matches exactly, wherever it is
+			if !folded || !set[idx] {
+				handle(idx, value, vt)
+			}
+			return nil
+		})
+		if err != nil {
+			d.el = append(d.el, errors.Wrap(err, "Invalid JSON"))
+		}
+		for idx, c := range counts {
+			if c > 1 {
+				d.o.duplicateKeys(prefix+fm.values[idx].name, c)
+			}
+		}
+	}
+	for i, ok := range found {
+		if !ok && fm.values[i].required {
+			n := prefix + fm.values[i].name
+			d.fail(n, errors.Errorf("Required field %s is missing from JSON", n))
+		}
+	}
+	for _, g := range fm.oneOf {
+		var present []string
+		names := make([]string, len(g.fields))
+		for i, idx := range g.fields {
+			names[i] = prefix + fm.values[idx].name
+			if found[idx] {
+				present = append(present, names[i])
+			}
+		}
+		if len(present) == 0 {
+			d.fail(names[0], errors.Errorf("Exactly one of fields %s must be present in JSON, got none", strings.Join(names, ", ")))
+		} else if len(present) > 1 {
+			d.fail(present[0], errors.Errorf("Exactly one of fields %s must be present in JSON, got %s", strings.Join(names, ", "), strings.Join(present, ", ")))
+		}
+	}
+	if fm.presence != "" {
+		d.presence(se.FieldByName(fm.presence), d.res.Modified[before:], prefix)
+	}
+	if fm.extras != "" {
+		d.extras(fm, data, se.FieldByName(fm.extras))
+	}
+	if fm.raw != "" {
+		rv := se.FieldByName(fm.raw)
+		//the top-level struct gets the whole input, before any of it is trimmed, rewritten, or selected by WithRootPath
+		src := d.source(data)
+		if prefix == "" {
+			src = d.input
+		}
+		rv.Set(reflect.ValueOf(append([]byte(nil), src...)).Convert(rv.Type()))
+	}
+	if d.o.disallowUnknown && fm.extras == "" {
+		unknownKeys(fm, data, func(key []byte, _ []byte, _ jsonparser.ValueType) {
+			n := prefix + string(key)
+			d.fail(n, errors.Errorf("Unknown field %s in JSON", n))
+		})
+	}
+	if prefix == "" && d.o.unknownKeyHandler != nil {
+		unknownKeys(fm, data, func(key []byte, _ []byte, _ jsonparser.ValueType) {
+			d.o.unknownKeyHandler(key)
+		})
+	}
+}
+
+// extras fills the map in ev, from a field tagged with modtracker:"extras", with the raw values of the keys in data
+// that don't match any field. The map is left untouched when there are no such keys.
+func (d *decodeState) extras(fm *fieldMap, data []byte, ev reflect.Value) {
+	m := map[string]json.RawMessage{}
+	unknownKeys(fm, data, func(key []byte, value []byte, vt jsonparser.ValueType) {
+		value = d.source(value)
+		if vt == jsonparser.String {
+			value = append(append([]byte{'"'}, value...), '"')
+		}
+		m[string(key)] = append(json.RawMessage(nil), value...)
+	})
+	if len(m) > 0 {
+		ev.Set(reflect.ValueOf(m))
+	}
+}
+
+// unknownKeys calls fn with each key in the JSON object in data that doesn't match any field, along with its value.
+func unknownKeys(fm *fieldMap, data []byte, fn func(key []byte, value []byte, vt jsonparser.ValueType)) {
+	jsonparser.ObjectEach(data, func(key []byte, value []byte, vt jsonparser.ValueType, _ int) error {
+		k := fm.normalize(key)
+		if _, _, ok := fm.match(k); !ok && !fm.known[k] {
+			fn(key, value, vt)
+		}
+		return nil
+	})
+}
+
+// normalize returns key as it is matched against the JSON keys in fm, after applying the function passed to
+// WithKeyNormalizer, if any.
+func (fm *fieldMap) normalize(key []byte) string {
+	if fm.normalizer == nil {
+		return string(key)
+	}
+	return fm.normalizer(string(key))
+}
+
+// match returns the index in fm.values of the field whose JSON key or aliases match the normalized key k, and whether
+// the key only matches when ignoring case, as it may for fields tagged modtracker:"ci".
+func (fm *fieldMap) match(k string) (idx int, folded bool, ok bool) {
+	if idx, ok := fm.byKey[k]; ok {
+		return idx, false, true
+	}
+	if fm.ciKeys != nil {
+		if idx, ok := fm.ciKeys[strings.ToLower(k)]; ok {
+			return idx, true, true
+		}
+	}
+	return 0, false, false
+}
+
+// presence fills the map in pv, from a field tagged with modtracker:"presence", with the modified fields.
+func (d *decodeState) presence(pv reflect.Value, modified []string, prefix string) {
+	m := reflect.MakeMapWithSize(pv.Type(), len(modified))
+	v := reflect.ValueOf(true)
+	if pv.Type().Elem().Kind() != reflect.Bool {
+		v = reflect.Zero(pv.Type().Elem())
+	}
+	for _, n := range modified {
+		m.SetMapIndex(reflect.ValueOf(strings.TrimPrefix(n, prefix)).Convert(pv.Type().Key()), v)
+	}
+	pv.Set(m)
+}
+
+// field populates a single struct field from a JSON value. It returns false if the value could not be assigned. If
+// first is false, the field was already populated from another JSON key and isn't recorded again.
+func (d *decodeState) field(fValue *fieldValue, value []byte, vt jsonparser.ValueType, se reflect.Value, prefix string, first bool) bool {
+	o := d.o
+	t := fValue.t
+	n := prefix + fValue.name
+	target := se.FieldByIndex(fValue.index)
+	if o.valueInterceptor != nil {
+		handled, err := o.valueInterceptor(n, vt, value)
+		if err != nil {
+			d.fail(n, errors.Wrapf(err, "Intercepting field %s", n))
+			return false
+		}
+		if handled {
+			d.handled(fValue, vt, n, first)
+			return true
+		}
+	}
+	if fn, ok := o.fieldUnmarshalers[n]; ok {
+		return d.custom(fValue, fn, value, vt, target, n, first)
+	}
+	//an empty object can only stand for null in place of a struct or a map
+	objectPtr := fValue.kind == reflect.Ptr && (fValue.internalKind == reflect.Struct || fValue.internalKind == reflect.Map)
+	if o.emptyObjectAsNull && objectPtr && vt == jsonparser.Object && isEmptyObject(value) {
+		return d.assign(fValue, reflect.Zero(t), jsonparser.Null, target, n, first)
+	}
+	fv := reflect.New(fValue.internalType)
+	if fValue.internalKind == reflect.Interface && o.interfaceResolver != nil && vt != jsonparser.Null {
+		return d.resolve(fValue, value, vt, fv, target, n, first)
+	}
+	if fValue.internalKind == reflect.Interface && vt == jsonparser.Object {
+		if p := polymorphicFor(fValue.internalType); p != nil {
+			return d.polymorphic(p, fValue, value, fv, target, n, first)
+		}
+	}
+	switch vt {
+	case jsonparser.String:
+		if o.maxStringLength > 0 && len(value) > o.maxStringLength {
+			d.fail(n, errors.Errorf("String value for field %s exceeds maximum length of %d bytes", n, o.maxStringLength))
+			return false
+		}
+		if fValue.internalType == timeType && (o.timeLayout != "" || o.timeLocation != nil) {
+			if !d.parseTime(value, fv, n) {
+				return false
+			}
+		} else if fValue.unmarshaler {
+			err := unmarshalQuoted(value, fv.Interface())
+			if err != nil {
+				d.fail(n, errors.Wrapf(err, "JSON unmarshaling field %s", n))
+				return false
+			}
+		} else if fValue.textUnmarshaler {
+			s, _ := jsonparser.ParseString(value)
+			err := fv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+			if err != nil {
+				d.fail(n, errors.Wrapf(err, "Invalid value in JSON for field %s", n))
+				return false
+			}
+		} else if fValue.internalType == urlType {
+			s, _ := jsonparser.ParseString(value)
+			u, err := url.Parse(s)
+			if err != nil {
+				d.fail(n, errors.Wrapf(err, "Invalid value in JSON for field %s", n))
+				return false
+			}
+			fv.Elem().Set(reflect.ValueOf(*u))
+		} else if fValue.binaryUnmarshaler {
+			s, _ := jsonparser.ParseString(value)
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err == nil {
+				err = fv.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+			}
+			if err != nil {
+				d.fail(n, errors.Wrapf(err, "Invalid value in JSON for field %s", n))
+				return false
+			}
+		} else if fValue.bytes() {
+			s, _ := jsonparser.ParseString(value)
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				d.fail(n, errors.Wrapf(err, "Invalid value in JSON, expected base64 for field %s", n))
+				return false
+			}
+			fv.Elem().SetBytes(b)
+		} else if fValue.kind == reflect.Func {
+			s, _ := jsonparser.ParseString(value)
+			fn, ok := o.funcResolver[s]
+			if !ok {
+				d.fail(n, errors.Errorf("Invalid value in JSON, unknown function %s for field %s", s, n))
+				return false
+			}
+			fnv := reflect.ValueOf(fn)
+			if !fnv.IsValid() || !fnv.Type().AssignableTo(t) {
+				d.fail(n, errors.Errorf("Function %s has type %T, which cannot be assigned to field %s of type %s", s, fn, n, t))
+				return false
+			}
+			fv.Elem().Set(fnv)
+		} else if names := fValue.enumNames(); names != nil {
+			s, _ := jsonparser.ParseString(value)
+			i, ok := names[s]
+			if !ok {
+				d.fail(n, errors.Errorf("Invalid value in JSON, unknown %s value %q for field %s", fValue.internalType, s, n))
+				return false
+			}
+			if fValue.intType {
 				fv.Elem().SetInt(i)
-			case fValue.uintType:
-				i, _ := jsonparser.ParseInt(value)
+			} else {
 				fv.Elem().SetUint(uint64(i))
-			case fValue.floatType:
-				f, _ := jsonparser.ParseFloat(value)
-				fv.Elem().SetFloat(f)
-			default:
-				el = append(el, errors.Errorf("Invalid type in JSON, expected %s for field %s, got Number", fValue.internalType, n))
-				return
-			}
-		case jsonparser.Object, jsonparser.Array:
-			err = json.Unmarshal(value, fv.Interface())
+			}
+		} else if o.numberParser != nil && (fValue.intType || fValue.uintType || fValue.floatType) {
+			s, _ := jsonparser.ParseString(value)
+			v, err := o.numberParser([]byte(s), fValue.internalKind)
 			if err != nil {
-				el = append(el, errors.Wrap(err, "JSON unmarshaling"))
-				return
+				d.fail(n, errors.Wrapf(err, "Invalid value in JSON for field %s", n))
+				return false
 			}
-		case jsonparser.Boolean:
-			err := validateType(fValue.internalType, fValue.internalKind, n, reflect.Bool, "Boolean")
+			if !v.IsValid() || !v.Type().ConvertibleTo(fValue.internalType) {
+				d.fail(n, errors.Errorf("Number parser returned a value that cannot be assigned to field %s of type %s", n, fValue.internalType))
+				return false
+			}
+			fv.Elem().Set(v.Convert(fValue.internalType))
+		} else if o.looseBooleans && fValue.internalKind == reflect.Bool {
+			s, _ := jsonparser.ParseString(value)
+			b, err := parseLooseBool(s, n)
 			if err != nil {
-				el = append(el, err)
-				return
+				d.fail(n, err)
+				return false
 			}
-			b, _ := jsonparser.ParseBoolean(value)
 			fv.Elem().SetBool(b)
-		case jsonparser.Null:
-			if fValue.pointerType {
-				fv = reflect.Zero(t)
-			} else {
-				el = append(el, errors.Errorf("Invalid type in JSON, cannot assign null to field %s", n))
-				return
+		} else if (o.stringlyTyped || fValue.quoted) && fValue.scalar() {
+			s, _ := jsonparser.ParseString(value)
+			err := parseScalar(fValue, fv.Elem(), s, n)
+			if err != nil {
+				d.fail(n, err)
+				return false
 			}
-		default:
-			el = append(el, (errors.Errorf("Unexpected jsonparser value type %d", vt)))
-			return
+		} else if fValue.quoted && fValue.internalKind == reflect.String {
+			s, _ := jsonparser.ParseString(value)
+			if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+				d.fail(n, errors.Errorf("Invalid value in JSON, expected a quoted string for field %s", n))
+				return false
+			}
+			s, err := jsonparser.ParseString([]byte(s[1 : len(s)-1]))
+			if err != nil {
+				d.fail(n, errors.Wrapf(err, "Invalid value in JSON for field %s", n))
+				return false
+			}
+			if o.trimStrings && !fValue.noTrim {
+				s = strings.TrimSpace(s)
+			}
+			fv.Elem().SetString(s)
+		} else {
+			err := validateType(fValue.internalType, fValue.internalKind, n, reflect.String, "String")
+			if err != nil {
+				d.fail(n, err)
+				return false
+			}
+			s, _ := jsonparser.ParseString(value)
+			if o.trimStrings && !fValue.noTrim {
+				s = strings.TrimSpace(s)
+			}
+			fv.Elem().SetString(s)
 		}
-		target := se.FieldByName(n)
-		switch fValue.kind {
-		case reflect.Ptr:
-			target.Set(fv)
-		case reflect.Slice, reflect.Map:
-			if vt == jsonparser.Null {
-				target.Set(fv)
-			} else {
-				target.Set(fv.Elem())
+	case jsonparser.Number:
+		switch {
+		case fValue.unmarshaler && fValue.internalType != timeType:
+			if err := json.Unmarshal(value, fv.Interface()); err != nil {
+				d.fail(n, errors.Wrapf(err, "JSON unmarshaling field %s", n))
+				return false
+			}
+		case (fValue.intType || fValue.uintType || fValue.floatType) && !o.lenientNumbers && hasLeadingZero(value):
+			d.fail(n, errors.Errorf("Invalid value in JSON, number %s for field %s has a leading zero", value, n))
+			return false
+		case fValue.intType:
+			//the bytes are parsed directly, since converting them to a string would allocate for every number
+			i, err := jsonparser.ParseInt(value)
+			if err != nil || fv.Elem().OverflowInt(i) {
+				d.fail(n, errors.Errorf("Invalid value in JSON, cannot convert %q to %s for field %s", value, fValue.internalType, n))
+				return false
+			}
+			fv.Elem().SetInt(i)
+		case fValue.floatType:
+			f, err := jsonparser.ParseFloat(value)
+			if err != nil || fv.Elem().OverflowFloat(f) {
+				d.fail(n, errors.Errorf("Invalid value in JSON, cannot convert %q to %s for field %s", value, fValue.internalType, n))
+				return false
+			}
+			fv.Elem().SetFloat(f)
+		case fValue.uintType:
+			if len(value) > 0 && value[0] == '-' {
+				d.fail(n, errors.Errorf("Invalid value in JSON, cannot assign negative number %s to unsigned field %s", value, n))
+				return false
+			}
+			//only the values too large for an int64 need the string
+			i, err := jsonparser.ParseInt(value)
+			u := uint64(i)
+			if err != nil {
+				u, err = strconv.ParseUint(string(value), 10, 64)
+			}
+			if err != nil || fv.Elem().OverflowUint(u) {
+				d.fail(n, errors.Errorf("Invalid value in JSON, cannot assign %s to %s field %s", value, fValue.internalType, n))
+				return false
+			}
+			fv.Elem().SetUint(u)
+		case fValue.internalType == timeType:
+			i, err := jsonparser.ParseInt(value)
+			if err != nil {
+				d.fail(n, errors.Errorf("Invalid value in JSON, expected a Unix timestamp for field %s, got %s", n, value))
+				return false
 			}
+			fv.Elem().Set(reflect.ValueOf(unixTime(i, o.unixTimeUnit)))
+		case fValue.internalType == numberType:
+			fv.Elem().SetString(string(value))
+		case o.coerceNumbers && fValue.internalKind == reflect.String:
+			fv.Elem().SetString(string(value))
+		case o.looseBooleans && fValue.internalKind == reflect.Bool:
+			if string(value) != "0" && string(value) != "1" {
+				d.fail(n, errors.Errorf("Invalid value in JSON, cannot assign %s to bool field %s", value, n))
+				return false
+			}
+			fv.Elem().SetBool(value[0] == '1')
 		default:
-			target.Set(fv.Elem())
+			d.fail(n, errors.Errorf("Invalid type in JSON, expected %s for field %s, got Number", fValue.internalType, n))
+			return false
+		}
+	case jsonparser.Object, jsonparser.Array:
+		if vt == jsonparser.Object && (fValue.child != nil || fValue.tracked || fValue.elem != nil) {
+			return d.nested(fValue, value, target, n, first)
+		}
+		if !fValue.unmarshaler && !acceptsContainer(fValue.internalKind, vt) {
+			jsonType := "Object"
+			if vt == jsonparser.Array {
+				jsonType = "Array"
+			}
+			d.fail(n, errors.Errorf("Invalid type in JSON, expected %s for field %s, got %s", fValue.internalType, n, jsonType))
+			return false
+		}
+		if vt == jsonparser.Array && !d.checkArrayLength(fValue, value, n) {
+			return false
+		}
+		if vt == jsonparser.Array && fValue.bytes() {
+			if !d.byteArray(value, fv, n) {
+				return false
+			}
+			break
+		}
+		if vt == jsonparser.Object && fValue.internalKind == reflect.Map && !fValue.unmarshaler {
+			if el := checkJSONType(fValue.internalType, value, vt, n); el != nil {
+				for _, err := range el {
+					d.fail(n, err)
+				}
+				return false
+			}
+		}
+		if o.mergeIntoExisting && vt == jsonparser.Object {
+			switch {
+			case fValue.kind == reflect.Ptr && !target.IsNil():
+				fv = target
+			case fValue.kind == reflect.Struct || fValue.kind == reflect.Map:
+				fv = target.Addr()
+			}
+		}
+		err := json.Unmarshal(value, fv.Interface())
+		if err != nil {
+			d.fail(n, errors.Wrapf(err, "JSON unmarshaling field %s", n))
+			return false
+		}
+	case jsonparser.Boolean:
+		if fValue.unmarshaler {
+			if err := json.Unmarshal(value, fv.Interface()); err != nil {
+				d.fail(n, errors.Wrapf(err, "JSON unmarshaling field %s", n))
+				return false
+			}
+			break
+		}
+		err := validateType(fValue.internalType, fValue.internalKind, n, reflect.Bool, "Boolean")
+		if err != nil {
+			d.fail(n, err)
+			return false
+		}
+		b, _ := jsonparser.ParseBoolean(value)
+		fv.Elem().SetBool(b)
+	case jsonparser.Null:
+		if fValue.pointerType {
+			fv = reflect.Zero(t)
+		} else if fValue.unmarshaler {
+			//like encoding/json, a value that unmarshals itself decides what null means, starting from its current value
+			fv.Elem().Set(target)
+			if err := json.Unmarshal(value, fv.Interface()); err != nil {
+				d.fail(n, errors.Wrapf(err, "JSON unmarshaling field %s", n))
+				return false
+			}
+		} else {
+			d.fail(n, errors.Errorf("Invalid type in JSON, cannot assign null to field %s", n))
+			return false
 		}
-		modified = append(modified, n)
-	}, fm.names...)
+	default:
+		d.fail(n, errors.Errorf("Unexpected jsonparser value type %d", vt))
+		return false
+	}
+	return d.assign(fValue, fv, vt, target, n, first)
+}
 
-	if el == nil {
-		return modified, nil
+// assign stores fv, the pointer to the value decoded from a JSON value of type vt, or the zero value of the field for
+// null, in the field target and records the field as modified.
+func (d *decodeState) assign(fValue *fieldValue, fv reflect.Value, vt jsonparser.ValueType, target reflect.Value, n string, first bool) bool {
+	//values out of range are rejected before they reach the field
+	if (fValue.min != nil || fValue.max != nil) && vt != jsonparser.Null {
+		if err := checkBounds(fValue, fv, n); err != nil {
+			d.fail(n, err)
+			return false
+		}
 	}
-	return nil, el
+	//nv is the value to store in the field, which is transformed and validated before the field is touched or anything
+	//is recorded, so a rejected value leaves no trace
+	var nv reflect.Value
+	switch fValue.kind {
+	case reflect.Ptr:
+		nv = fv
+	case reflect.Slice, reflect.Map, reflect.Func:
+		if vt == jsonparser.Null {
+			nv = fv
+		} else if vt == jsonparser.Array && fValue.kind == reflect.Slice && d.o.sliceAppend {
+			nv = reflect.AppendSlice(target, fv.Elem())
+			//the maximum applies to the slice the field ends up with, not only to the elements being appended
+			if limit := d.maxLength(fValue); limit > 0 && nv.Len() > limit {
+				d.fail(n, errors.Errorf("Appending to field %s gives %d elements, more than the maximum of %d", n, nv.Len(), limit))
+				return false
+			}
+		} else {
+			nv = fv.Elem()
+		}
+	default:
+		nv = fv.Elem()
+	}
+	if fn, ok := d.o.fieldTransforms[n]; ok && vt != jsonparser.Null {
+		v := reflect.New(fValue.t).Elem()
+		v.Set(nv)
+		if err := fn(v); err != nil {
+			d.fail(n, errors.Wrapf(err, "Transform failed for field %s", n))
+			return false
+		}
+		nv = v
+	}
+	if d.o.fieldValidator != nil {
+		modified := d.res.Modified[:len(d.res.Modified):len(d.res.Modified)]
+		if err := d.o.fieldValidator(n, nv, modified); err != nil {
+			d.fail(n, errors.Wrapf(err, "Invalid value for field %s", n))
+			return false
+		}
+	}
+	if d.detailed && vt == jsonparser.Null && first {
+		d.res.Nulled = append(d.res.Nulled, n)
+		if fValue.pointerType && !target.IsNil() {
+			d.res.Cleared = append(d.res.Cleared, n)
+		}
+	}
+	if d.o.ignoreZeroValues && vt != jsonparser.Null && fValue.kind != reflect.Ptr && isZero(nv) {
+		return true
+	}
+	if d.snapshot != nil && first {
+		d.snapshot[n] = target.Interface()
+	}
+	target.Set(nv)
+	if d.detailed && first && fValue.kind == reflect.Ptr && vt != jsonparser.Null {
+		d.res.SetNonNull = append(d.res.SetNonNull, n)
+	}
+	if first {
+		d.res.Modified = append(d.res.Modified, n)
+	}
+	return true
+}
+
+// zeroer is implemented by types whose zero value isn't the Go zero value, such as time.Time.
+type zeroer interface {
+	IsZero() bool
+}
+
+// isZero reports whether v holds a zero value, using the IsZero method of its type if there is one.
+func isZero(v reflect.Value) bool {
+	if z, ok := v.Interface().(zeroer); ok {
+		return z.IsZero()
+	}
+	if v.CanAddr() {
+		if z, ok := v.Addr().Interface().(zeroer); ok {
+			return z.IsZero()
+		}
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// resolve populates an interface field, or a pointer to an interface, with the value returned by the function passed
+// to WithInterfaceResolver.
+func (d *decodeState) resolve(fValue *fieldValue, value []byte, vt jsonparser.ValueType, fv reflect.Value, target reflect.Value, n string, first bool) bool {
+	if vt == jsonparser.String {
+		value = append(append([]byte{'"'}, value...), '"')
+	}
+	v, err := d.o.interfaceResolver(n, value)
+	if err != nil {
+		d.fail(n, errors.Wrapf(err, "Resolving the type of field %s", n))
+		return false
+	}
+	if !v.IsValid() || !v.Type().AssignableTo(fValue.internalType) {
+		d.fail(n, errors.Errorf("Interface resolver returned a value that cannot be assigned to field %s of type %s", n, fValue.internalType))
+		return false
+	}
+	fv.Elem().Set(v)
+	return d.assign(fValue, fv, vt, target, n, first)
+}
+
+// byteArray stores the bytes in the JSON array of numbers in value, such as [104, 105], in fv, a pointer to a slice of
+// bytes.
+func (d *decodeState) byteArray(value []byte, fv reflect.Value, n string) bool {
+	b := []byte{}
+	ok := true
+	i := 0
+	jsonparser.ArrayEach(value, func(v []byte, vt jsonparser.ValueType, _ int, _ error) {
+		u, err := strconv.ParseUint(string(v), 10, 8)
+		if vt != jsonparser.Number || err != nil {
+			d.fail(n, errors.Errorf("Invalid value in JSON, expected a number from 0 to 255 for element %d of field %s, got %s", i, n, v))
+			ok = false
+		}
+		b = append(b, byte(u))
+		i++
+	})
+	if ok {
+		fv.Elem().SetBytes(b)
+	}
+	return ok
+}
+
+// checkArrayLength returns false if the JSON array in value has more elements than allowed for the field by a
+// modtracker:"maxlen" tag or WithMaxSliceLength.
+func (d *decodeState) checkArrayLength(fValue *fieldValue, value []byte, n string) bool {
+	limit := d.maxLength(fValue)
+	if limit <= 0 {
+		return true
+	}
+	count := 0
+	jsonparser.ArrayEach(value, func([]byte, jsonparser.ValueType, int, error) {
+		count++
+	})
+	if count > limit {
+		d.fail(n, errors.Errorf("Array for field %s has %d elements, more than the maximum of %d", n, count, limit))
+		return false
+	}
+	return true
+}
+
+// maxLength returns the maximum number of elements allowed for the field by a modtracker:"maxlen" tag or
+// WithMaxSliceLength, or a value less than or equal to zero if there is none.
+func (d *decodeState) maxLength(fValue *fieldValue) int {
+	if fValue.maxLen != 0 {
+		return fValue.maxLen
+	}
+	return d.o.maxSliceLength
+}
+
+// parseTime stores the time in the JSON string value in fv, using the layout and location passed to WithTimeLayout
+// and WithTimeLocation.
+func (d *decodeState) parseTime(value []byte, fv reflect.Value, n string) bool {
+	layout, loc := d.o.timeLayout, d.o.timeLocation
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	s, _ := jsonparser.ParseString(value)
+	tm, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		d.fail(n, errors.Errorf("Invalid value in JSON, cannot parse %q as a time with layout %s for field %s", s, layout, n))
+		return false
+	}
+	fv.Elem().Set(reflect.ValueOf(tm))
+	return true
+}
+
+// quoteBuffers holds the buffers used by unmarshalQuoted to put the quotes back around JSON strings.
+var quoteBuffers = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 64)
+		return &b
+	},
+}
+
+// unmarshalQuoted passes the contents of a JSON string, without the surrounding quotes, to the json.Unmarshaler in v.
+// The quoted string is built in a pooled buffer, since json.Unmarshaler implementations must copy the data they
+// retain, and UnmarshalJSON is called directly to skip the validation done by json.Unmarshal.
+func unmarshalQuoted(value []byte, v interface{}) error {
+	bp := quoteBuffers.Get().(*[]byte)
+	b := append(append(append((*bp)[:0], '"'), value...), '"')
+	var err error
+	if u, ok := v.(json.Unmarshaler); ok {
+		err = u.UnmarshalJSON(b)
+	} else {
+		err = json.Unmarshal(b, v)
+	}
+	//don't keep the buffers for unusually long strings alive
+	if cap(b) <= 4096 {
+		*bp = b
+		quoteBuffers.Put(bp)
+	}
+	return err
+}
+
+// fail records an error that prevented the field n from being populated.
+func (d *decodeState) fail(n string, err error) {
+	d.el = append(d.el, err)
+	if d.o.errorCounter != nil {
+		d.o.errorCounter(n)
+	}
+}
+
+// custom populates a field using an unmarshaling function registered with WithUnmarshalerFor. The field is recorded
+// like one populated by assign.
+func (d *decodeState) custom(fValue *fieldValue, fn FieldUnmarshaler, value []byte, vt jsonparser.ValueType, target reflect.Value, n string, first bool) bool {
+	//fn overwrites the field, so what it held before has to be looked at first
+	var old interface{}
+	if d.snapshot != nil && first {
+		old = target.Interface()
+	}
+	wasSet := fValue.pointerType && !target.IsNil()
+	if err := fn(value, vt, target); err != nil {
+		d.fail(n, errors.Wrapf(err, "Custom unmarshaling of field %s", n))
+		return false
+	}
+	if d.snapshot != nil && first {
+		d.snapshot[n] = old
+	}
+	if d.detailed && vt == jsonparser.Null && first && wasSet {
+		d.res.Cleared = append(d.res.Cleared, n)
+	}
+	d.handled(fValue, vt, n, first)
+	return true
+}
+
+// handled records the field n, populated outside of the built-in unmarshaling logic from a JSON value of type vt, as
+// modified.
+func (d *decodeState) handled(fValue *fieldValue, vt jsonparser.ValueType, n string, first bool) {
+	if !first {
+		return
+	}
+	if d.detailed && vt == jsonparser.Null {
+		d.res.Nulled = append(d.res.Nulled, n)
+	} else if d.detailed && fValue.kind == reflect.Ptr {
+		d.res.SetNonNull = append(d.res.SetNonNull, n)
+	}
+	d.res.Modified = append(d.res.Modified, n)
+}
+
+// unixTime converts a Unix timestamp, counted in units of unit since January 1, 1970 UTC, to a UTC time.
+func unixTime(i int64, unit time.Duration) time.Time {
+	if unit >= time.Second {
+		return time.Unix(i*int64(unit/time.Second), 0).UTC()
+	}
+	perSecond := int64(time.Second / unit)
+	return time.Unix(i/perSecond, (i%perSecond)*int64(unit)).UTC()
+}
+
+// parseLooseBool converts one of the strings accepted by WithLooseBooleans into a bool.
+func parseLooseBool(s string, n string) (bool, error) {
+	switch s {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, errors.Errorf("Invalid value in JSON, cannot assign %q to bool field %s", s, n)
+}
+
+// hasLeadingZero reports whether the JSON number in value starts with a zero followed by another digit, like 05.
+func hasLeadingZero(value []byte) bool {
+	if len(value) > 0 && value[0] == '-' {
+		value = value[1:]
+	}
+	return len(value) > 1 && value[0] == '0' && value[1] >= '0' && value[1] <= '9'
+}
+
+// parseScalar sets the int, uint, float, or bool in v from the text of a JSON string.
+func parseScalar(fValue *fieldValue, v reflect.Value, s string, n string) error {
+	var err error
+	switch {
+	case fValue.intType:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 64)
+		if err == nil && v.OverflowInt(i) {
+			err = strconv.ErrRange
+		}
+		if err == nil {
+			v.SetInt(i)
+		}
+	case fValue.uintType:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, 64)
+		if err == nil && v.OverflowUint(u) {
+			err = strconv.ErrRange
+		}
+		if err == nil {
+			v.SetUint(u)
+		}
+	case fValue.floatType:
+		var f float64
+		f, err = strconv.ParseFloat(s, v.Type().Bits())
+		if err == nil {
+			v.SetFloat(f)
+		}
+	case fValue.internalKind == reflect.Bool:
+		switch s {
+		case "true":
+			v.SetBool(true)
+		case "false":
+			v.SetBool(false)
+		default:
+			err = strconv.ErrSyntax
+		}
+	}
+	if err != nil {
+		return errors.Errorf("Invalid value in JSON, cannot convert %q to %s for field %s", s, fValue.internalType, n)
+	}
+	return nil
 }
 
 type fieldMap struct {
-	names  [][]string
-	values []fieldValue
+	t        reflect.Type //struct type the field map was built for
+	names    [][]string
+	indexes  []int //index into values for each entry in names
+	values   []fieldValue
+	presence string          //name of the field tagged modtracker:"presence", if any
+	extras   string          //name of the field tagged modtracker:"extras", if any
+	raw      string          //name of the field tagged modtracker:"raw", if any
+	required bool            //at least one field is tagged modtracker:"required"
+	known    map[string]bool //JSON keys in names
+	ci       []int           //indexes into names of the keys of fields tagged modtracker:"ci"
+	oneOf    []oneOfGroup    //groups of fields tagged modtracker:"oneof=group", in declaration order
+
+	normalizer func(string) string //function passed to WithKeyNormalizer, already applied to the keys in names
+	byKey      map[string]int      //index into values for each JSON key in names
+	concrete   *sync.Map           //*fieldMap for each polymorphicMap, built with the same options as the field map
+	ciKeys     map[string]int      //index into values for each lowercased JSON key in names listed in ci
+}
+
+// oneOfGroup is a group of mutually exclusive fields, exactly one of which must be present in the JSON.
+type oneOfGroup struct {
+	name   string
+	fields []int //indexes into values
 }
 
 type fieldValue struct {
-	kind         reflect.Kind
-	internalType reflect.Type
-	internalKind reflect.Kind
-	t            reflect.Type //type in struct
-	name         string       //name in struct
-	pointerType  bool
-	unmarshaler  bool
-	intType      bool
-	uintType     bool
-	floatType    bool
-}
-
-func buildJSONFieldMap(s interface{}) (fieldMap, error) {
+	kind              reflect.Kind
+	internalType      reflect.Type
+	internalKind      reflect.Kind
+	t                 reflect.Type //type in struct
+	name              string       //name in struct
+	index             []int        //index sequence of the field in the struct, for reflect.Value.FieldByIndex
+	key               string       //JSON key, not including aliases
+	pointerType       bool
+	unmarshaler       bool
+	textUnmarshaler   bool //only set when the type doesn't implement json.Unmarshaler
+	binaryUnmarshaler bool //only set when the type implements neither json.Unmarshaler nor encoding.TextUnmarshaler
+	quoted            bool //json tag has the string option
+	intType           bool
+	uintType          bool
+	floatType         bool
+	child             *fieldMap   //fields of a nested struct tracked with WithNestedTracking
+	tracked           bool        //nested struct reports its own modified fields through Modifiable
+	elem              *fieldValue //value type of a map of nested structs tracked with WithNestedTracking
+	required          bool        //field is tagged modtracker:"required" and must be present in the JSON
+	deprecated        bool        //field is tagged modtracker:"deprecated"
+	noTrim            bool        //field is tagged modtracker:"notrim" and isn't affected by WithTrimStrings
+	min               *float64    //lower bound from a modtracker:"min=n" tag, if any
+	max               *float64    //upper bound from a modtracker:"max=n" tag, if any
+	maxLen            int         //maximum number of elements from a modtracker:"maxlen=n" tag, or 0
+}
+
+// tagOptions is the comma-separated list of options that follows the name in a json struct tag.
+type tagOptions string
+
+// parseTag splits a json struct tag into its name and its options.
+func parseTag(tag string) (string, tagOptions) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], tagOptions(tag[idx+1:])
+	}
+	return tag, tagOptions("")
+}
+
+// Contains reports whether the options include the named option.
+func (o tagOptions) Contains(optionName string) bool {
+	s := string(o)
+	for s != "" {
+		var next string
+		if i := strings.Index(s, ","); i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if s == optionName {
+			return true
+		}
+		s = next
+	}
+	return false
+}
+
+// modtrackerTag holds the settings of a modtracker struct tag. The tag is a comma-separated list of settings, each of
+// which is either a name or a name=value pair, such as `modtracker:"presence"` or `modtracker:"alias=email"`. A setting
+// can be repeated to provide more than one value.
+type modtrackerTag map[string][]string
+
+func parseModtrackerTag(tag string) modtrackerTag {
+	if tag == "" {
+		return nil
+	}
+	out := modtrackerTag{}
+	for _, v := range strings.Split(tag, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		name, value := v, ""
+		if idx := strings.Index(v, "="); idx != -1 {
+			name, value = v[:idx], v[idx+1:]
+		}
+		out[name] = append(out[name], value)
+	}
+	return out
+}
+
+// has reports whether the tag contains the named setting.
+func (mt modtrackerTag) has(name string) bool {
+	_, ok := mt[name]
+	return ok
+}
+
+// get returns the values of the named setting, in the order they appear in the tag.
+func (mt modtrackerTag) get(name string) []string {
+	return mt[name]
+}
+
+// isPresenceType reports whether t can hold the presence of fields: a map with string keys and bool or empty struct
+// values.
+func isPresenceType(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+	et := t.Elem()
+	return et.Kind() == reflect.Bool || (et.Kind() == reflect.Struct && et.NumField() == 0)
+}
+
+// parseBounds sets the range of values allowed in the numeric field fv from the min and max settings in mt.
+func parseBounds(fv *fieldValue, mt modtrackerTag) error {
+	for _, b := range []struct {
+		name  string
+		bound **float64
+	}{{"min", &fv.min}, {"max", &fv.max}} {
+		v := mt.get(b.name)
+		if len(v) == 0 {
+			continue
+		}
+		if !fv.intType && !fv.uintType && !fv.floatType {
+			return errors.Errorf("Field %s tagged with %s must be a number", fv.name, b.name)
+		}
+		f, err := strconv.ParseFloat(v[len(v)-1], 64)
+		if err != nil {
+			return errors.Errorf("Invalid %s %q for field %s", b.name, v[len(v)-1], fv.name)
+		}
+		*b.bound = &f
+	}
+	if fv.min != nil && fv.max != nil && *fv.min > *fv.max {
+		return errors.Errorf("Invalid range for field %s, min is greater than max", fv.name)
+	}
+	return nil
+}
+
+// checkBounds returns an error if the number in v, the populated field described by fValue, is outside of the range
+// set by the modtracker:"min" and modtracker:"max" tags.
+func checkBounds(fValue *fieldValue, v reflect.Value, n string) error {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	var f float64
+	switch {
+	case fValue.intType:
+		f = float64(v.Int())
+	case fValue.uintType:
+		f = float64(v.Uint())
+	default:
+		f = v.Float()
+	}
+	if fValue.min != nil && f < *fValue.min {
+		return errors.Errorf("Invalid value in JSON, %v is less than the minimum of %v for field %s", v, *fValue.min, n)
+	}
+	if fValue.max != nil && f > *fValue.max {
+		return errors.Errorf("Invalid value in JSON, %v is greater than the maximum of %v for field %s", v, *fValue.max, n)
+	}
+	return nil
+}
+
+// bytes returns true for fields holding a []byte, or another slice of bytes, that doesn't unmarshal itself.
+func (fv *fieldValue) bytes() bool {
+	return fv.internalKind == reflect.Slice && fv.internalType.Elem().Kind() == reflect.Uint8 &&
+		!fv.unmarshaler && !fv.textUnmarshaler && !fv.binaryUnmarshaler
+}
+
+// enumNames returns the names registered with RegisterEnum for the type of an integer field, or nil if there are none.
+func (fv *fieldValue) enumNames() map[string]int64 {
+	if !fv.intType && !fv.uintType {
+		return nil
+	}
+	return enumNames(fv.internalType)
+}
+
+// scalar returns true for fields holding an int, uint, float, or bool.
+func (fv *fieldValue) scalar() bool {
+	return fv.intType || fv.uintType || fv.floatType || fv.internalKind == reflect.Bool
+}
+
+func buildJSONFieldMap(s interface{}, o *options) (fieldMap, error) {
 	st := reflect.TypeOf(s)
+	if st == nil {
+		return fieldMap{}, errors.New("Only works on pointers to structs: got nil")
+	}
+	if st.Kind() == reflect.Struct {
+		return fieldMap{}, errors.Errorf("Only works on pointers to structs: got struct value; pass a pointer like (*%s)(nil)", st)
+	}
 	if st.Kind() != reflect.Ptr {
 		return fieldMap{}, errors.New("Only works on pointers to structs")
 	}
-	stInner := st.Elem()
+	fm, err := buildJSONFieldMapForType(st.Elem(), o)
+	if err != nil {
+		return fieldMap{}, err
+	}
+	return fm, checkFieldNames(fm, o)
+}
+
+// checkFieldNames returns an error for each field name passed to an Option, such as WithUnmarshalerFor, that doesn't
+// name a field of fm, so a typo is caught when the Unmarshaler is built instead of silently doing nothing.
+func checkFieldNames(fm fieldMap, o *options) error {
+	var el errorList
+	check := func(option string, names []string) {
+		sort.Strings(names)
+		for _, name := range names {
+			fv, ok := fm.findField(name)
+			switch {
+			case !ok:
+				el = append(el, errors.Errorf("%s refers to unknown field %s", option, name))
+			case option == "WithFieldTransform" && fv != nil && fv.tracksFields():
+				//such a field is never assigned as a whole, so there is no value to transform
+				el = append(el, errors.Errorf("%s cannot transform field %s, whose fields are tracked individually", option, name))
+			}
+		}
+	}
+	unmarshalers := make([]string, 0, len(o.fieldUnmarshalers))
+	for k := range o.fieldUnmarshalers {
+		unmarshalers = append(unmarshalers, k)
+	}
+	check("WithUnmarshalerFor", unmarshalers)
+	transforms := make([]string, 0, len(o.fieldTransforms))
+	for k := range o.fieldTransforms {
+		transforms = append(transforms, k)
+	}
+	check("WithFieldTransform", transforms)
+	if el != nil {
+		return el.asError()
+	}
+	return nil
+}
+
+func buildJSONFieldMapForType(stInner reflect.Type, o *options) (fieldMap, error) {
+	out, err := buildStructFieldMap(stInner, o, map[reflect.Type]*fieldMap{})
+	if err != nil {
+		return fieldMap{}, err
+	}
+	out.concrete = &sync.Map{}
+	if o.requireModifiable && !reflect.PtrTo(stInner).Implements(modifiableType) {
+		return fieldMap{}, errors.Errorf("Type %s doesn't implement Modifiable: add a GetModified() []string method", stInner)
+	}
+
+	seen := make(map[string]string, len(out.names))
+	for i, v := range out.names {
+		seen[v[0]] = out.values[out.indexes[i]].name
+	}
+	aliases := make([]string, 0, len(o.aliases))
+	for k := range o.aliases {
+		aliases = append(aliases, k)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		name := o.aliases[alias]
+		k := alias
+		if o.keyNormalizer != nil {
+			k = o.keyNormalizer(k)
+		}
+		idx := -1
+		for i, v := range out.values {
+			if v.name == name {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return fieldMap{}, errors.Errorf("Alias %s refers to unknown field %s", alias, name)
+		}
+		if prev, ok := seen[k]; ok {
+			return fieldMap{}, errors.Errorf("Duplicate JSON key %s for fields %s and %s", k, prev, name)
+		}
+		seen[k] = name
+		out.names = append(out.names, []string{k})
+		out.indexes = append(out.indexes, idx)
+	}
+	out.known = knownKeys(out.names)
+	if err := out.setByKey(); err != nil {
+		return fieldMap{}, err
+	}
+	return *out, nil
+}
+
+func buildStructFieldMap(stInner reflect.Type, o *options, built map[reflect.Type]*fieldMap) (*fieldMap, error) {
 	if stInner.Kind() != reflect.Struct {
-		return fieldMap{}, errors.New("Only works on pointers to structs")
+		return nil, errors.New("Only works on pointers to structs")
 	}
-	out := fieldMap{}
-	out.names = make([][]string, stInner.NumField())
-	out.values = make([]fieldValue, stInner.NumField())
+	out := &fieldMap{t: stInner}
+	built[stInner] = out
+	seen := map[string]string{}
+	//problems with individual fields are collected, so that they can all be fixed at once
+	var el errorList
+	out.names = make([][]string, 0, stInner.NumField())
+	out.indexes = make([]int, 0, stInner.NumField())
+	out.values = make([]fieldValue, 0, stInner.NumField())
 	for i := 0; i < stInner.NumField(); i++ {
 		sf := stInner.Field(i)
-		//skip over any chan fields or func fields
-		if sf.Type.Kind() == reflect.Func || sf.Type.Kind() == reflect.Chan {
+		//skip over unexported fields, since they cannot be set
+		if sf.PkgPath != "" {
+			continue
+		}
+		//skip over any chan fields or func fields, unless func fields can be resolved by name
+		if (sf.Type.Kind() == reflect.Func && o.funcResolver == nil) || sf.Type.Kind() == reflect.Chan {
+			continue
+		}
+		mt := parseModtrackerTag(sf.Tag.Get("modtracker"))
+		if mt.has("presence") {
+			if !isPresenceType(sf.Type) {
+				el = append(el, errors.Errorf("Field %s tagged as presence must be of type map[string]bool or map[string]struct{}", sf.Name))
+				continue
+			}
+			if out.presence != "" {
+				el = append(el, errors.Errorf("Fields %s and %s are both tagged as presence", out.presence, sf.Name))
+				continue
+			}
+			out.presence = sf.Name
 			continue
 		}
-		var fieldName string
-		if name := sf.Tag.Get("json"); len(name) > 0 {
-			fieldName = strings.Split(name, ",")[0]
+		if mt.has("extras") {
+			if sf.Type != extrasType {
+				el = append(el, errors.Errorf("Field %s tagged as extras must be of type map[string]json.RawMessage", sf.Name))
+				continue
+			}
+			if out.extras != "" {
+				el = append(el, errors.Errorf("Fields %s and %s are both tagged as extras", out.extras, sf.Name))
+				continue
+			}
+			out.extras = sf.Name
+			continue
+		}
+		if mt.has("raw") {
+			//the field is set by converting a []byte, which a slice of a named byte type doesn't allow
+			if sf.Type.Kind() != reflect.Slice || !bytesType.ConvertibleTo(sf.Type) {
+				el = append(el, errors.Errorf("Field %s tagged as raw must be of type []byte or json.RawMessage", sf.Name))
+				continue
+			}
+			if out.raw != "" {
+				el = append(el, errors.Errorf("Fields %s and %s are both tagged as raw", out.raw, sf.Name))
+				continue
+			}
+			out.raw = sf.Name
+			continue
 		}
-		if fieldName == "-" {
+		if mt.has("inline") {
+			if err := inlineStruct(out, sf, seen, o, built); err != nil {
+				el = append(el, errorsOf(err)...)
+			}
 			continue
 		}
+		//like encoding/json, only a tag of exactly "-" skips the field, while "-," names it "-"
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName, tagOpts := parseTag(tag)
+		if v := mt.get("v" + strconv.Itoa(o.version)); o.version > 0 && len(v) > 0 {
+			fieldName = v[len(v)-1]
+		}
 		if fieldName == "" {
 			fieldName = sf.Name
+			if o.kebabCase {
+				fieldName = kebabCase(sf.Name)
+			}
+		}
+		if o.keyNormalizer != nil {
+			fieldName = o.keyNormalizer(fieldName)
+		}
+		if prev, ok := seen[fieldName]; ok {
+			el = append(el, errors.Errorf("Duplicate JSON key %s for fields %s and %s", fieldName, prev, sf.Name))
+			continue
 		}
+		seen[fieldName] = sf.Name
 		t := sf.Type
 		k := t.Kind()
 		it := t
@@ -283,7 +1739,9 @@ func buildJSONFieldMap(s interface{}) (fieldMap, error) {
 		}
 		itk := it.Kind()
 		um := (t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType))
-		pt := t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Ptr
+		tum := !um && reflect.PtrTo(it).Implements(textUnmarshalerType)
+		bum := !um && !tum && it != urlType && reflect.PtrTo(it).Implements(binaryUnmarshalerType)
+		pt := t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Ptr || t.Kind() == reflect.Func
 		intType := false
 		uintType := false
 		floatType := false
@@ -296,20 +1754,207 @@ func buildJSONFieldMap(s interface{}) (fieldMap, error) {
 			floatType = true
 		}
 
-		out.names[i] = []string{fieldName}
+		if mt.has("ci") {
+			out.ci = append(out.ci, len(out.names))
+		}
+		out.names = append(out.names, []string{fieldName})
+		out.indexes = append(out.indexes, len(out.values))
+		for _, alias := range mt.get("alias") {
+			if o.keyNormalizer != nil {
+				alias = o.keyNormalizer(alias)
+			}
+			if prev, ok := seen[alias]; ok {
+				el = append(el, errors.Errorf("Duplicate JSON key %s for fields %s and %s", alias, prev, sf.Name))
+				continue
+			}
+			seen[alias] = sf.Name
+			if mt.has("ci") {
+				out.ci = append(out.ci, len(out.names))
+			}
+			out.names = append(out.names, []string{alias})
+			out.indexes = append(out.indexes, len(out.values))
+		}
 
-		out.values[i] = fieldValue{
-			t:            t,
-			name:         sf.Name,
-			kind:         k,
-			internalType: it,
-			unmarshaler:  um,
-			internalKind: itk,
-			pointerType:  pt,
-			intType:      intType,
-			uintType:     uintType,
-			floatType:    floatType,
+		fv := fieldValue{
+			t:                 t,
+			name:              sf.Name,
+			index:             sf.Index,
+			key:               fieldName,
+			kind:              k,
+			internalType:      it,
+			unmarshaler:       um,
+			textUnmarshaler:   tum,
+			binaryUnmarshaler: bum,
+			internalKind:      itk,
+			pointerType:       pt,
+			intType:           intType,
+			uintType:          uintType,
+			floatType:         floatType,
+		}
+		fv.quoted = tagOpts.Contains("string") && (fv.scalar() || itk == reflect.String)
+		fv.required = mt.has("required")
+		fv.deprecated = mt.has("deprecated")
+		fv.noTrim = mt.has("notrim")
+		if err := parseBounds(&fv, mt); err != nil {
+			el = append(el, err)
+		}
+		if v := mt.get("maxlen"); len(v) > 0 {
+			if itk != reflect.Slice && itk != reflect.Array {
+				el = append(el, errors.Errorf("Field %s tagged with maxlen must be a slice or array", sf.Name))
+			} else if maxLen, err := strconv.Atoi(v[len(v)-1]); err != nil || maxLen <= 0 {
+				el = append(el, errors.Errorf("Invalid maxlen %q for field %s", v[len(v)-1], sf.Name))
+			} else {
+				fv.maxLen = maxLen
+			}
+		}
+		for _, g := range mt.get("oneof") {
+			out.addOneOf(g, len(out.values))
+		}
+		out.required = out.required || fv.required
+		if o.nestedTracking {
+			var err error
+			switch {
+			case k == reflect.Map && t.Key().Kind() == reflect.String:
+				fv.elem, err = buildNestedMapElem(t.Elem(), o, built)
+			case itk == reflect.Struct:
+				fv.child, fv.tracked, err = buildNestedStruct(it, o, built)
+			}
+			if err != nil {
+				el = append(el, errorsOf(wrapErrors(err, "Field %s", sf.Name))...)
+			}
 		}
+		out.values = append(out.values, fv)
+	}
+	//the fields of inlined structs can only be compared with the others once all of them are collected
+	byName := make(map[string]int, len(out.values))
+	for i, v := range out.values {
+		prev, ok := byName[v.name]
+		if !ok {
+			byName[v.name] = i
+			continue
+		}
+		inlined, other := v, out.values[prev]
+		if len(inlined.index) == 1 {
+			inlined, other = other, inlined
+		}
+		el = append(el, errors.Errorf("Field %s of inlined field %s conflicts with field %s", inlined.name, stInner.Field(inlined.index[0]).Name, other.name))
+	}
+	out.known = knownKeys(out.names)
+	out.normalizer = o.keyNormalizer
+	if err := out.setByKey(); err != nil {
+		el = append(el, errorsOf(err)...)
+	}
+	if el != nil {
+		return nil, el.asError()
 	}
 	return out, nil
 }
+
+// target returns the struct to populate from s, a pointer to either a struct of the type fm was built for or a struct
+// that embeds that type, in which case the embedded struct is populated.
+func (fm *fieldMap) target(s interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(s)
+	if v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		se := v.Elem()
+		if se.Type() == fm.t {
+			return se, nil
+		}
+		if f, ok := se.Type().FieldByName(fm.t.Name()); ok && f.Anonymous && f.Type == fm.t {
+			return se.FieldByIndex(f.Index), nil
+		}
+	}
+	return reflect.Value{}, errors.Errorf("Unmarshaler for %s cannot decode into %T", fm.t, s)
+}
+
+// addOneOf adds the field at idx in values to the named group of mutually exclusive fields.
+func (fm *fieldMap) addOneOf(group string, idx int) {
+	for i := range fm.oneOf {
+		if fm.oneOf[i].name == group {
+			fm.oneOf[i].fields = append(fm.oneOf[i].fields, idx)
+			return
+		}
+	}
+	fm.oneOf = append(fm.oneOf, oneOfGroup{name: group, fields: []int{idx}})
+}
+
+// inlineStruct adds the fields of the struct in the field sf, tagged with modtracker:"inline", to out, as if they were
+// declared in the struct containing sf. seen holds the JSON keys already in out.
+func inlineStruct(out *fieldMap, sf reflect.StructField, seen map[string]string, o *options, built map[reflect.Type]*fieldMap) error {
+	if sf.Type.Kind() != reflect.Struct {
+		return errors.Errorf("Field %s tagged as inline must be a struct", sf.Name)
+	}
+	inner, err := buildStructFieldMap(sf.Type, o, built)
+	if err != nil {
+		return wrapErrors(err, "Field %s", sf.Name)
+	}
+	var el errorList
+	for _, v := range []struct{ tag, name string }{{"presence", inner.presence}, {"extras", inner.extras}, {"raw", inner.raw}} {
+		if v.name != "" {
+			el = append(el, errors.Errorf("Field %s of inlined field %s cannot be tagged as %s", v.name, sf.Name, v.tag))
+		}
+	}
+	if el != nil {
+		return el.asError()
+	}
+	for i, v := range inner.names {
+		name := sf.Name + "." + inner.values[inner.indexes[i]].name
+		if prev, ok := seen[v[0]]; ok {
+			return errors.Errorf("Duplicate JSON key %s for fields %s and %s", v[0], prev, name)
+		}
+		seen[v[0]] = name
+	}
+	for _, i := range inner.ci {
+		out.ci = append(out.ci, len(out.names)+i)
+	}
+	for i, v := range inner.names {
+		out.names = append(out.names, v)
+		out.indexes = append(out.indexes, len(out.values)+inner.indexes[i])
+	}
+	for _, v := range inner.values {
+		v.index = append(append([]int(nil), sf.Index...), v.index...)
+		out.values = append(out.values, v)
+	}
+	for _, g := range inner.oneOf {
+		for _, idx := range g.fields {
+			out.addOneOf(g.name, len(out.values)-len(inner.values)+idx)
+		}
+	}
+	out.required = out.required || inner.required
+	return nil
+}
+
+// setByKey fills byKey and ciKeys from names and indexes. It returns an error for each pair of fields tagged
+// modtracker:"ci" whose JSON keys are the same when ignoring case, since a key could match either of them.
+func (fm *fieldMap) setByKey() error {
+	fm.byKey = make(map[string]int, len(fm.names))
+	for i, v := range fm.names {
+		fm.byKey[v[0]] = fm.indexes[i]
+	}
+	fm.ciKeys = nil
+	if len(fm.ci) == 0 {
+		return nil
+	}
+	var el errorList
+	fm.ciKeys = make(map[string]int, len(fm.ci))
+	for _, i := range fm.ci {
+		k := strings.ToLower(fm.names[i][0])
+		if prev, ok := fm.ciKeys[k]; ok && prev != fm.indexes[i] {
+			el = append(el, errors.Errorf("Duplicate JSON key %s when ignoring case for fields %s and %s", k, fm.values[prev].name, fm.values[fm.indexes[i]].name))
+			continue
+		}
+		fm.ciKeys[k] = fm.indexes[i]
+	}
+	if el != nil {
+		return el.asError()
+	}
+	return nil
+}
+
+// knownKeys returns the set of JSON keys in names.
+func knownKeys(names [][]string) map[string]bool {
+	known := make(map[string]bool, len(names))
+	for _, v := range names {
+		known[v[0]] = true
+	}
+	return known
+}
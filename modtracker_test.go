@@ -19,7 +19,15 @@ package modtracker
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/buger/jsonparser"
+	"github.com/capitalone/modtracker/internal/testtypes"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -291,7 +299,7 @@ func TestUnmarshalJSONInvalid(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, 0, len(modified))
 	assert.Equal(t, "Doe", *ts.LastName)
-	assert.Equal(t, 0, *ts.Age)
+	assert.Nil(t, ts.Age)
 	assert.Equal(t, 0, ts.FavoriteNum)
 	assert.Nil(t, ts.FirstName)
 	assert.Nil(t, ts.MiddleName)
@@ -323,3 +331,3588 @@ func TestCustomJSONSerialilzerString(t *testing.T) {
 	assert.Equal(t, st, *ts.T)
 	assert.Equal(t, st, ts.T2)
 }
+
+func TestDuplicateJSONKey(t *testing.T) {
+	type TSample struct {
+		A string
+		B string `json:"A"`
+	}
+
+	_, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Duplicate JSON key A for fields A and B")
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"A": "x"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Nil(t, modified)
+}
+
+func TestMergeIntoExisting(t *testing.T) {
+	type Inner struct {
+		Address string
+		Zip     string
+	}
+	type TSample struct {
+		Inner  *Inner
+		Value  Inner
+		Labels map[string]string
+	}
+
+	data := `
+	{
+		"Inner": {"Address": "742 Evergreen Terr."},
+		"Value": {"Zip": "49007"},
+		"Labels": {"b": "2"}
+	}
+	`
+	existing := &Inner{Address: "1 Main St.", Zip: "12345"}
+	ts := TSample{
+		Inner:  existing,
+		Value:  Inner{Address: "1 Main St.", Zip: "12345"},
+		Labels: map[string]string{"a": "1"},
+	}
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithMergeIntoExisting())
+	assert.Nil(t, err)
+	modified, err := u([]byte(data), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inner", "Value", "Labels"}, modified)
+	assert.True(t, existing == ts.Inner)
+	assert.Equal(t, Inner{Address: "742 Evergreen Terr.", Zip: "12345"}, *ts.Inner)
+	assert.Equal(t, Inner{Address: "1 Main St.", Zip: "49007"}, ts.Value)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, ts.Labels)
+
+	ts = TSample{Inner: &Inner{Address: "1 Main St.", Zip: "12345"}}
+	_, err = UnmarshalJSON([]byte(data), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, Inner{Address: "742 Evergreen Terr."}, *ts.Inner)
+}
+
+func TestMaxStringLength(t *testing.T) {
+	type TSample struct {
+		FirstName *string
+		LastName  string
+	}
+
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithMaxStringLength(5))
+	assert.Nil(t, err)
+	var ts TSample
+	modified, err := u([]byte(`{"FirstName": "Homer", "LastName": "Simpson"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Nil(t, modified)
+	assert.Contains(t, err.Error(), "String value for field LastName exceeds maximum length of 5 bytes")
+	assert.Equal(t, "Homer", *ts.FirstName)
+	assert.Equal(t, "", ts.LastName)
+}
+
+func TestBuildJSONUnmarshalerForType(t *testing.T) {
+	type TSample struct {
+		FirstName *string `json:"firstName"`
+		Age       int     `json:"age"`
+	}
+
+	for _, rt := range []reflect.Type{reflect.TypeOf(TSample{}), reflect.TypeOf(&TSample{})} {
+		u, err := BuildJSONUnmarshalerForType(rt)
+		assert.Nil(t, err)
+		var ts TSample
+		modified, err := u([]byte(`{"firstName": "Homer", "age": 37}`), &ts)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"FirstName", "Age"}, modified)
+		assert.Equal(t, "Homer", *ts.FirstName)
+		assert.Equal(t, 37, ts.Age)
+	}
+
+	_, err := BuildJSONUnmarshalerForType(reflect.TypeOf(10))
+	assert.NotNil(t, err)
+	_, err = BuildJSONUnmarshalerForType(nil)
+	assert.NotNil(t, err)
+}
+
+func TestUnmarshalJSONDetailed(t *testing.T) {
+	type TSample struct {
+		FirstName  *string           `json:"firstName"`
+		MiddleName *string           `json:"middleName"`
+		Tags       []string          `json:"tags"`
+		Labels     map[string]string `json:"labels"`
+		Age        int               `json:"age"`
+	}
+
+	data := `
+	{
+		"firstName": null,
+		"middleName": null,
+		"tags": null,
+		"labels": {"a": "b"},
+		"age": 37
+	}
+	`
+	first := "Homer"
+	ts := TSample{FirstName: &first, Tags: []string{"x"}}
+	u, err := BuildDetailedJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	res, err := u([]byte(data), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"FirstName", "MiddleName", "Tags", "Labels", "Age"}, res.Modified)
+	assert.Equal(t, []string{"FirstName", "MiddleName", "Tags"}, res.Nulled)
+	assert.Equal(t, []string{"FirstName", "Tags"}, res.Cleared)
+	assert.Nil(t, ts.FirstName)
+	assert.Nil(t, ts.Tags)
+
+	res, err = UnmarshalJSONDetailed([]byte(`{"age": "old"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Nil(t, res.Modified)
+}
+
+func TestWithAliases(t *testing.T) {
+	type TSample struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithAliases(map[string]string{
+		"emailAddress": "Email",
+		"mail":         "Email",
+	}))
+	assert.Nil(t, err)
+	var ts TSample
+	modified, err := u([]byte(`{"emailAddress": "homer@example.com", "name": "Homer"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Email", "Name"}, modified)
+	assert.Equal(t, "homer@example.com", ts.Email)
+
+	ts = TSample{}
+	modified, err = u([]byte(`{"email": "a@example.com", "mail": "b@example.com"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Email"}, modified)
+	assert.Equal(t, "b@example.com", ts.Email)
+
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithAliases(map[string]string{"x": "Missing"}))
+	assert.NotNil(t, err)
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithAliases(map[string]string{"name": "Email"}))
+	assert.NotNil(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	type TSample struct {
+		FirstName *string
+		LastName  string `json:"lastName"`
+		Counts    map[int]string
+		Inner     *struct {
+			Address string
+		}
+		modified []string
+	}
+	assert.Nil(t, Check((*TSample)(nil)))
+
+	type TBad struct {
+		Double   **string
+		Complex  complex128
+		Stringer fmt.Stringer
+		Keys     map[float64]string
+		Any      interface{}
+	}
+	err := Check((*TBad)(nil))
+	assert.NotNil(t, err)
+	assert.Equal(t, 4, len(err.(errorList)))
+	assert.Contains(t, err.Error(), "Field Double")
+	assert.Contains(t, err.Error(), "Field Complex")
+	assert.Contains(t, err.Error(), "Field Stringer")
+	assert.Contains(t, err.Error(), "Field Keys")
+
+	type TDup struct {
+		A string
+		B string `json:"A"`
+	}
+	assert.NotNil(t, Check((*TDup)(nil)))
+	assert.NotNil(t, Check(TDup{}))
+}
+
+type Account struct {
+	Owner    string  `json:"owner"`
+	Balance  float64 `json:"balance"`
+	modified []string
+}
+
+func (a *Account) UnmarshalJSON(data []byte) error {
+	var err error
+	a.modified, err = UnmarshalJSON(data, a)
+	return err
+}
+
+func (a *Account) GetModified() []string {
+	return a.modified
+}
+
+func TestNestedTracking(t *testing.T) {
+	type Address struct {
+		Street string
+		Zip    *string
+	}
+	type TSample struct {
+		Name     string
+		Home     *Address
+		Work     Address
+		Primary  *Account
+		Accounts map[string]*Account
+		Balances map[string]Account
+	}
+
+	data := `
+	{
+		"Name": "Homer",
+		"Home": {"Street": "742 Evergreen Terr.", "Zip": null},
+		"Work": {},
+		"Primary": {"owner": "Homer"},
+		"Accounts": {
+			"checking": {"balance": 10.5},
+			"savings": null,
+			"closed": {}
+		},
+		"Balances": {"a": {"owner": "Marge", "balance": 3}}
+	}
+	`
+	u, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+	ts := TSample{Accounts: map[string]*Account{"old": {}}}
+	res, err := u([]byte(data), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"Name",
+		"Home.Street",
+		"Home.Zip",
+		"Work",
+		"Primary.Owner",
+		"Accounts[checking].Balance",
+		"Accounts[savings]",
+		"Accounts[closed]",
+		"Balances[a].Owner",
+		"Balances[a].Balance",
+	}, res.Modified)
+	assert.Equal(t, []string{"Home.Zip", "Accounts[savings]"}, res.Nulled)
+	assert.Equal(t, "742 Evergreen Terr.", ts.Home.Street)
+	assert.Equal(t, "Homer", ts.Primary.Owner)
+	assert.Equal(t, []string{"Owner"}, ts.Primary.GetModified())
+	assert.Equal(t, 3, len(ts.Accounts))
+	assert.Equal(t, 10.5, ts.Accounts["checking"].Balance)
+	assert.Nil(t, ts.Accounts["savings"])
+	assert.Equal(t, 3.0, ts.Balances["a"].Balance)
+
+	res, err = u([]byte(`{"Accounts": null, "Balances": {}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Accounts", "Balances"}, res.Modified)
+	assert.Nil(t, ts.Accounts)
+	assert.Equal(t, 0, len(ts.Balances))
+
+	_, err = u([]byte(`{"Accounts": {"x": 12}, "Home": {"Zip": 5}}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "field Accounts[x]")
+	assert.Contains(t, err.Error(), "field Home.Zip")
+
+	type Node struct {
+		Value int
+		Next  *Node
+	}
+	nu, err := BuildJSONUnmarshaler((*Node)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+	var n Node
+	modified, err := nu([]byte(`{"Value": 1, "Next": {"Next": {"Value": 3}}}`), &n)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Value", "Next.Next.Value"}, modified)
+	assert.Equal(t, 3, n.Next.Next.Value)
+}
+
+func TestURLAndIP(t *testing.T) {
+	type TSample struct {
+		URL   url.URL  `json:"site"`
+		URL2  *url.URL `json:"site2"`
+		IP    net.IP   `json:"addr"`
+		Level level    `json:"level"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"site":"https://x.com","site2":"https://y.com/a?b=c","addr":"10.0.0.1","level":"HIGH"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"URL", "URL2", "IP", "Level"}, modified)
+	assert.Equal(t, "https://x.com", ts.URL.String())
+	assert.Equal(t, "y.com", ts.URL2.Host)
+	assert.Equal(t, "b=c", ts.URL2.RawQuery)
+	assert.True(t, net.IPv4(10, 0, 0, 1).Equal(ts.IP))
+	assert.Equal(t, level(2), ts.Level)
+
+	_, err = UnmarshalJSON([]byte(`{"addr":"not an ip"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "field IP")
+	_, err = UnmarshalJSON([]byte(`{"site":"%zz"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "field URL")
+}
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "LOW":
+		*l = 1
+	case "HIGH":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", text)
+	}
+	return nil
+}
+
+func TestWithUnmarshalerFor(t *testing.T) {
+	type TSample struct {
+		Created  time.Time `json:"created"`
+		Modified time.Time `json:"modified"`
+	}
+
+	epoch := func(value []byte, vt jsonparser.ValueType, v reflect.Value) error {
+		if vt != jsonparser.Number {
+			return fmt.Errorf("expected a number, got %s", vt)
+		}
+		i, err := jsonparser.ParseInt(value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(time.Unix(i, 0).UTC()))
+		return nil
+	}
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithUnmarshalerFor("Created", epoch))
+	assert.Nil(t, err)
+	var ts TSample
+	modified, err := u([]byte(`{"created": 1257894000, "modified": "2009-11-10T23:00:00Z"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Created", "Modified"}, modified)
+	assert.Equal(t, ts.Modified, ts.Created)
+
+	_, err = u([]byte(`{"created": "2009-11-10T23:00:00Z"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Custom unmarshaling of field Created")
+
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithUnmarshalerFor("Craeted", epoch), WithUnmarshalerFor("Modified.Year", epoch))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: 2 Errors found:\n"+
+		"WithUnmarshalerFor refers to unknown field Craeted\n"+
+		"WithUnmarshalerFor refers to unknown field Modified.Year\n")
+}
+
+func TestWithUnmarshalerForNested(t *testing.T) {
+	type TInner struct {
+		Zip string `json:"zip"`
+	}
+	type TSample struct {
+		Inner    TInner             `json:"inner"`
+		Accounts map[string]*TInner `json:"accounts"`
+		Note     *string            `json:"note"`
+	}
+
+	upper := func(value []byte, vt jsonparser.ValueType, v reflect.Value) error {
+		if vt == jsonparser.Null {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		v.Set(reflect.ValueOf(strings.ToUpper(string(value))))
+		return nil
+	}
+	_, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithUnmarshalerFor("Inner.Zip", upper),
+		WithUnmarshalerFor("Accounts[a.b].Zip", upper))
+	assert.Nil(t, err)
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithUnmarshalerFor("Inner.Street", upper),
+		WithUnmarshalerFor("Accounts.Zip", upper), WithUnmarshalerFor("Accounts[x]", upper))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: 3 Errors found:\n"+
+		"WithUnmarshalerFor refers to unknown field Accounts.Zip\n"+
+		"WithUnmarshalerFor refers to unknown field Accounts[x]\n"+
+		"WithUnmarshalerFor refers to unknown field Inner.Street\n")
+	_, err = NewDecoder((*TSample)(nil), WithUnmarshalerFor("Inner.Zip", upper))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: WithUnmarshalerFor refers to unknown field Inner.Zip")
+
+	//the field is recorded like any other, so clearing it is reported
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithUnmarshalerFor("Note", upper))
+	assert.Nil(t, err)
+	note := "hi"
+	ts := TSample{Note: &note}
+	res, err := unmarshal([]byte(`{"note": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Note"}, res.Nulled)
+	assert.Equal(t, []string{"Note"}, res.Cleared)
+	assert.Nil(t, ts.Note)
+}
+
+func TestWithStringlyTyped(t *testing.T) {
+	type TSample struct {
+		Age    int      `json:"age"`
+		Count  *uint8   `json:"count"`
+		Score  float64  `json:"score"`
+		Active bool     `json:"active"`
+		Admin  *bool    `json:"admin"`
+		Name   string   `json:"name"`
+		Ratio  *float32 `json:"ratio"`
+	}
+
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithStringlyTyped())
+	assert.Nil(t, err)
+	var ts TSample
+	data := `{"age":"37","count":"12","score":"9.5","active":"true","admin":false,"name":"Homer","ratio":0.5}`
+	modified, err := u([]byte(data), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(modified))
+	assert.Equal(t, 37, ts.Age)
+	assert.Equal(t, uint8(12), *ts.Count)
+	assert.Equal(t, 9.5, ts.Score)
+	assert.True(t, ts.Active)
+	assert.False(t, *ts.Admin)
+	assert.Equal(t, float32(0.5), *ts.Ratio)
+
+	_, err = u([]byte(`{"age":"old","count":"300","active":"yes"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, len(err.(errorList)))
+
+	_, err = UnmarshalJSON([]byte(`{"age":"37"}`), &ts)
+	assert.NotNil(t, err)
+}
+
+func TestWithErrorCounter(t *testing.T) {
+	type TSample struct {
+		Age  int    `json:"age"`
+		Name string `json:"name"`
+		Pet  string `json:"pet"`
+	}
+
+	counts := map[string]int{}
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithErrorCounter(func(fieldName string) {
+		counts[fieldName]++
+	}))
+	assert.Nil(t, err)
+	var ts TSample
+	_, err = u([]byte(`{"age": "old", "name": 12, "pet": "Santa's Little Helper"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, map[string]int{"Age": 1, "Name": 1}, counts)
+}
+
+func TestJSONTagOptions(t *testing.T) {
+	type TSample struct {
+		Count   int      `json:",string"`
+		Ratio   *float64 `json:"ratio,omitempty,string"`
+		Active  bool     `json:",omitempty"`
+		Quoted  string   `json:"quoted,string"`
+		Literal string   `json:"literal,omitempty"`
+	}
+
+	var ts TSample
+	data := `{"Count": "5", "ratio": "0.25", "Active": true, "quoted": "\"Homer\"", "literal": "Marge"}`
+	modified, err := UnmarshalJSON([]byte(data), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Ratio", "Active", "Quoted", "Literal"}, modified)
+	assert.Equal(t, 5, ts.Count)
+	assert.Equal(t, 0.25, *ts.Ratio)
+	assert.True(t, ts.Active)
+	assert.Equal(t, "Homer", ts.Quoted)
+	assert.Equal(t, "Marge", ts.Literal)
+
+	_, err = UnmarshalJSON([]byte(`{"Count": "five", "quoted": "Homer"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(err.(errorList)))
+}
+
+func TestUnmarshalJSONCache(t *testing.T) {
+	type TSample struct {
+		FirstName *string `json:"firstName"`
+		Age       int     `json:"age"`
+	}
+
+	for i := 0; i < 2; i++ {
+		var ts TSample
+		modified, err := UnmarshalJSON([]byte(`{"firstName": "Homer", "age": 37}`), &ts)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"FirstName", "Age"}, modified)
+		assert.Equal(t, 37, ts.Age)
+		_, ok := fieldMapCache.Load(reflect.TypeOf(&ts))
+		assert.True(t, ok)
+	}
+}
+
+func TestWithFuncResolver(t *testing.T) {
+	type TSample struct {
+		Name      string
+		Validator func(string) bool
+		Hook      func()
+	}
+
+	nonEmpty := func(s string) bool { return s != "" }
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithFuncResolver(map[string]interface{}{
+		"nonEmpty": nonEmpty,
+		"wrong":    func(int) bool { return false },
+	}))
+	assert.Nil(t, err)
+	ts := TSample{Hook: func() {}}
+	modified, err := u([]byte(`{"Name": "Homer", "Validator": "nonEmpty", "Hook": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Validator", "Hook"}, modified)
+	assert.True(t, ts.Validator("x"))
+	assert.False(t, ts.Validator(""))
+	assert.Nil(t, ts.Hook)
+
+	_, err = u([]byte(`{"Validator": "wrong", "Hook": "missing"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(err.(errorList)))
+
+	ts = TSample{}
+	modified, err = UnmarshalJSON([]byte(`{"Name": "Homer", "Validator": "nonEmpty"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Nil(t, ts.Validator)
+}
+
+func TestPresenceField(t *testing.T) {
+	type TSample struct {
+		FirstName *string         `json:"firstName"`
+		LastName  *string         `json:"lastName"`
+		Age       int             `json:"age"`
+		Present   map[string]bool `modtracker:"presence"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"firstName": "Homer", "lastName": null, "Present": {"x": true}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"FirstName", "LastName"}, modified)
+	assert.Equal(t, map[string]bool{"FirstName": true, "LastName": true}, ts.Present)
+
+	type TSet struct {
+		Age     int
+		Present map[string]struct{} `modtracker:"presence"`
+	}
+	var tset TSet
+	_, err = UnmarshalJSON([]byte(`{"Age": 37}`), &tset)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]struct{}{"Age": {}}, tset.Present)
+
+	type TBad struct {
+		Present []string `modtracker:"presence"`
+	}
+	_, err = BuildJSONUnmarshaler((*TBad)(nil))
+	assert.NotNil(t, err)
+}
+
+func TestNegativeUnsigned(t *testing.T) {
+	type TSample struct {
+		ID    uint   `json:"id"`
+		Count *uint8 `json:"count"`
+		Other int    `json:"other"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"id": -5, "count": -1, "other": -5}`), &ts)
+	assert.NotNil(t, err)
+	assert.Nil(t, modified)
+	assert.Contains(t, err.Error(), "cannot assign negative number -5 to unsigned field ID")
+	assert.Contains(t, err.Error(), "cannot assign negative number -1 to unsigned field Count")
+	assert.Equal(t, uint(0), ts.ID)
+	assert.Nil(t, ts.Count)
+	assert.Equal(t, -5, ts.Other)
+}
+
+type fuzzSample struct {
+	FirstName *string `json:"firstName"`
+	LastName  string  `json:"lastName"`
+	Age       *int
+	Count     uint8
+	Big       uint64
+	Ratio     float32
+	Active    *bool
+	Tags      []string
+	Labels    map[string]int
+	Counts    map[int]string
+	Inner     *struct {
+		Address string
+		Zip     *int
+	}
+	Value    struct{ A, B int }
+	When     *time.Time
+	Site     url.URL
+	Addr     net.IP
+	Level    level
+	Any      interface{}
+	Raw      json.RawMessage
+	Double   **int
+	Callback func()
+	Present  map[string]bool `modtracker:"presence"`
+	modified []string
+	ignored  int
+}
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	for _, v := range tests {
+		f.Add([]byte(v))
+	}
+	f.Add([]byte(`{"firstName": "Homer", "Age": 37, "Count": 300, "Big": 18446744073709551615, "Ratio": 1e300}`))
+	f.Add([]byte(`{"Tags": ["a"], "Labels": {"a": 1}, "Counts": {"1": "a"}, "Inner": {"Zip": null}, "Value": {"A": 1}}`))
+	f.Add([]byte(`{"When": "2009-11-10T23:00:00Z", "Site": "https://x.com", "Addr": "10.0.0.1", "Level": "LOW"}`))
+	f.Add([]byte(`{"Any": {"a": [1, 2]}, "Raw": {"b": true}, "Double": 5, "Callback": "x", "modified": ["x"], "ignored": 1}`))
+	f.Add([]byte(`{"Present": {"a": true}, "Age": -1, "Count": -1, "Active": "true", "lastName": null}`))
+	f.Add([]byte(`"":0`))
+	nested, err := BuildDetailedJSONUnmarshaler((*fuzzSample)(nil), WithNestedTracking(), WithMergeIntoExisting(), WithStringlyTyped())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s fuzzSample
+		UnmarshalJSON(data, &s)
+		var s2 fuzzSample
+		nested(data, &s2)
+	})
+}
+
+func TestUnexportedAndMalformed(t *testing.T) {
+	var s fuzzSample
+	modified, err := UnmarshalJSON([]byte(`{"lastName": "Simpson", "modified": ["x"], "ignored": 1}`), &s)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"LastName"}, modified)
+	assert.Nil(t, s.modified)
+	assert.Equal(t, 0, s.ignored)
+
+	_, err = UnmarshalJSON([]byte(`"":0`), &s)
+	assert.NotNil(t, err)
+}
+
+func TestUnixTimestamps(t *testing.T) {
+	type TSample struct {
+		T  time.Time  `json:"t"`
+		T2 *time.Time `json:"t2"`
+	}
+
+	expected := time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"t": 1257894000, "t2": 1257894000}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"T", "T2"}, modified)
+	assert.Equal(t, expected, ts.T)
+	assert.Equal(t, expected, *ts.T2)
+
+	u, err := BuildJSONUnmarshaler((*TSample)(nil), WithUnixTimeUnit(time.Millisecond))
+	assert.Nil(t, err)
+	ts = TSample{}
+	_, err = u([]byte(`{"t": 1257894000123, "t2": "2009-11-10T23:00:00Z"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.Add(123*time.Millisecond), ts.T)
+	assert.Equal(t, expected, ts.T2.UTC())
+
+	_, err = u([]byte(`{"t": 12.5}`), &ts)
+	assert.NotNil(t, err)
+}
+
+func TestWithModifiedOrder(t *testing.T) {
+	type Inner struct {
+		Address string
+		Zip     string
+	}
+	type TSample struct {
+		FirstName  *string
+		MiddleName *string
+		LastName   *string
+		Inner      *Inner
+		Age        int
+	}
+
+	data := []byte(`{"Age": 37, "Inner": {"Zip": "49007", "Address": "742 Evergreen Terr."}, "LastName": null, "FirstName": "Homer", "MiddleName": null}`)
+	var ts TSample
+	modified, err := UnmarshalJSON(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age", "Inner", "LastName", "FirstName", "MiddleName"}, modified)
+
+	u, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithModifiedOrder(DeclarationOrder), WithNestedTracking())
+	assert.Nil(t, err)
+	res, err := u(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"FirstName", "MiddleName", "LastName", "Inner.Address", "Inner.Zip", "Age"}, res.Modified)
+	assert.Equal(t, []string{"MiddleName", "LastName"}, res.Nulled)
+
+	type TNested struct {
+		Accounts map[string]Inner
+		Home     struct {
+			Inner Inner
+			Name  string
+		}
+	}
+	u, err = BuildDetailedJSONUnmarshaler((*TNested)(nil), WithModifiedOrder(DeclarationOrder), WithNestedTracking())
+	assert.Nil(t, err)
+	var tn TNested
+	res, err = u([]byte(`{"Home": {"Name": "x", "Inner": {"Zip": "1", "Address": "a"}}, "Accounts": {"b": {"Zip": "2"}, "a": {"Zip": "3", "Address": "c"}}}`), &tn)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Accounts[b].Zip", "Accounts[a].Address", "Accounts[a].Zip", "Home.Inner.Address", "Home.Inner.Zip", "Home.Name"}, res.Modified)
+}
+
+type normalized struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	seen  []string
+}
+
+func (n *normalized) PostDecode(modified []string) error {
+	n.seen = modified
+	for _, v := range modified {
+		if v == "Email" {
+			if !strings.Contains(n.Email, "@") {
+				return fmt.Errorf("invalid email %s", n.Email)
+			}
+			n.Email = strings.ToLower(n.Email)
+		}
+	}
+	return nil
+}
+
+func TestPostDecoder(t *testing.T) {
+	u, err := BuildJSONUnmarshaler((*normalized)(nil))
+	assert.Nil(t, err)
+	var n normalized
+	modified, err := u([]byte(`{"email": "Homer@Example.com"}`), &n)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Email"}, modified)
+	assert.Equal(t, []string{"Email"}, n.seen)
+	assert.Equal(t, "homer@example.com", n.Email)
+
+	modified, err = u([]byte(`{"email": "homer", "name": "Homer"}`), &n)
+	assert.NotNil(t, err)
+	assert.Nil(t, modified)
+	assert.Equal(t, "invalid email homer", err.Error())
+}
+
+func TestAliasTag(t *testing.T) {
+	type TSample struct {
+		Email string `json:"email" modtracker:"alias=emailAddress,alias=mail"`
+		Name  string `json:"name"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"emailAddress": "homer@example.com", "name": "Homer"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Email", "Name"}, modified)
+	assert.Equal(t, "homer@example.com", ts.Email)
+
+	ts = TSample{}
+	modified, err = UnmarshalJSON([]byte(`{"email": "a@example.com", "mail": "b@example.com"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Email"}, modified)
+	assert.Equal(t, "b@example.com", ts.Email)
+
+	type TDup struct {
+		Email string `json:"email" modtracker:"alias=name"`
+		Name  string `json:"name"`
+	}
+	_, err = BuildJSONUnmarshaler((*TDup)(nil))
+	assert.NotNil(t, err)
+}
+
+func TestNestedMapValidation(t *testing.T) {
+	type TSample struct {
+		Counts map[string]map[string]int `json:"counts"`
+		Lists  *map[string][]uint8       `json:"lists"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"counts": {"a": {"x": 1, "y": 2}, "b": {}}, "lists": {"a": [1, 2]}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Counts", "Lists"}, modified)
+	assert.Equal(t, map[string]map[string]int{"a": {"x": 1, "y": 2}, "b": {}}, ts.Counts)
+	assert.Equal(t, []uint8{1, 2}, (*ts.Lists)["a"])
+
+	ts = TSample{}
+	_, err = UnmarshalJSON([]byte(`{"counts": {"a": {"x": 1, "y": "two", "z": 1.5}, "b": 3}, "lists": {"a": [1, 300]}}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 4, len(err.(errorList)))
+	assert.Contains(t, err.Error(), "expected int for field Counts[a][y], got string")
+	assert.Contains(t, err.Error(), "cannot assign 1.5 to int field Counts[a][z]")
+	assert.Contains(t, err.Error(), "expected map[string]int for field Counts[b], got number")
+	assert.Contains(t, err.Error(), "cannot assign 300 to uint8 field Lists[a][1]")
+	assert.Nil(t, ts.Counts)
+}
+
+func TestExtras(t *testing.T) {
+	type TSample struct {
+		Name   string                     `json:"name" modtracker:"alias=fullName"`
+		Extras map[string]json.RawMessage `modtracker:"extras"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"name": "Bob", "fullName": "Bob Smith", "age": 42, "nick": "bobby", "tags": ["a", "b"], "Extras": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Equal(t, map[string]json.RawMessage{
+		"age":    json.RawMessage(`42`),
+		"nick":   json.RawMessage(`"bobby"`),
+		"tags":   json.RawMessage(`["a", "b"]`),
+		"Extras": json.RawMessage(`null`),
+	}, ts.Extras)
+
+	ts = TSample{}
+	_, err = UnmarshalJSON([]byte(`{"name": "Bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Nil(t, ts.Extras)
+
+	type TBad struct {
+		Extras map[string]interface{} `modtracker:"extras"`
+	}
+	_, err = BuildJSONUnmarshaler((*TBad)(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Extras tagged as extras must be of type map[string]json.RawMessage")
+}
+
+func TestLooseBooleans(t *testing.T) {
+	type TSample struct {
+		A bool  `json:"a"`
+		B *bool `json:"b"`
+		C bool  `json:"c"`
+		D bool  `json:"d"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithLooseBooleans())
+	assert.Nil(t, err)
+
+	ts := TSample{C: true}
+	modified, err := unmarshal([]byte(`{"a": 1, "b": "yes", "c": "0", "d": true}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"A", "B", "C", "D"}, modified)
+	assert.True(t, ts.A)
+	assert.True(t, *ts.B)
+	assert.False(t, ts.C)
+	assert.True(t, ts.D)
+
+	ts = TSample{}
+	_, err = unmarshal([]byte(`{"a": 2, "b": "maybe", "c": "no"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot assign 2 to bool field A")
+	assert.Contains(t, err.Error(), `cannot assign "maybe" to bool field B`)
+
+	ts = TSample{}
+	_, err = UnmarshalJSON([]byte(`{"a": 1}`), &ts)
+	assert.Contains(t, err.Error(), "Invalid type in JSON, expected bool for field A, got Number")
+}
+
+func TestIntMapKeys(t *testing.T) {
+	type TSample struct {
+		Counts map[int]string   `json:"counts"`
+		Sizes  map[uint8][]bool `json:"sizes"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"counts": {"1": "a", "-2": "b"}, "sizes": {"255": [true]}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Counts", "Sizes"}, modified)
+	assert.Equal(t, map[int]string{1: "a", -2: "b"}, ts.Counts)
+	assert.Equal(t, map[uint8][]bool{255: {true}}, ts.Sizes)
+
+	ts = TSample{}
+	_, err = UnmarshalJSON([]byte(`{"counts": {"1": "a", "x": "b"}, "sizes": {"256": []}}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `Invalid key in JSON, cannot use "x" as int key for field Counts`)
+	assert.Contains(t, err.Error(), `Invalid key in JSON, cannot use "256" as uint8 key for field Sizes`)
+	assert.Nil(t, ts.Counts)
+}
+
+func TestDecoder(t *testing.T) {
+	type TSample struct {
+		Name *string `json:"name"`
+		Age  int     `json:"age"`
+	}
+
+	dec, err := NewDecoder((*TSample)(nil), WithStringlyTyped())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := dec.Decode([]byte(`{"name": "Bob", "age": "42"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Age"}, modified)
+	assert.Equal(t, "Bob", *ts.Name)
+	assert.Equal(t, 42, ts.Age)
+
+	ts = TSample{}
+	modified, err = dec.DecodeReader(strings.NewReader(`{"age": 7}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+	assert.Equal(t, 7, ts.Age)
+
+	_, err = dec.Decode([]byte(`{"age": 7}`), &Account{})
+	assert.EqualError(t, err, "Unmarshaler for modtracker.TSample cannot decode into *modtracker.Account")
+	_, err = dec.Decode([]byte(`{"age": 7}`), (*TSample)(nil))
+	assert.EqualError(t, err, "Unmarshaler for modtracker.TSample cannot decode into *modtracker.TSample")
+
+	_, err = NewDecoder(TSample{})
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Only works on pointers to structs: got struct value; pass a pointer like (*modtracker.TSample)(nil)")
+}
+
+func TestFieldTransform(t *testing.T) {
+	type TSample struct {
+		Currency *string `json:"currency"`
+		Amount   int     `json:"amount"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil),
+		WithFieldTransform("Currency", func(v reflect.Value) error {
+			v.Elem().SetString(strings.ToUpper(v.Elem().String()))
+			return nil
+		}),
+		WithFieldTransform("Amount", func(v reflect.Value) error {
+			if v.Int() < 0 {
+				return fmt.Errorf("negative amount %d", v.Int())
+			}
+			return nil
+		}))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"currency": "usd", "amount": 10}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Currency", "Amount"}, modified)
+	assert.Equal(t, "USD", *ts.Currency)
+
+	modified, err = unmarshal([]byte(`{"currency": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Currency"}, modified)
+	assert.Nil(t, ts.Currency)
+
+	_, err = unmarshal([]byte(`{"amount": -1}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Transform failed for field Amount: negative amount -1")
+
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithFieldTransform("Ammount", func(reflect.Value) error { return nil }))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: WithFieldTransform refers to unknown field Ammount")
+
+	type TOuter struct {
+		Inner TSample `json:"inner"`
+	}
+	identity := func(reflect.Value) error { return nil }
+	_, err = BuildJSONUnmarshaler((*TOuter)(nil), WithNestedTracking(), WithFieldTransform("Inner", identity))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: WithFieldTransform cannot transform field Inner, whose fields are tracked individually")
+	_, err = BuildJSONUnmarshaler((*TOuter)(nil), WithNestedTracking(), WithFieldTransform("Inner.Amount", identity))
+	assert.Nil(t, err)
+	_, err = BuildJSONUnmarshaler((*TOuter)(nil), WithFieldTransform("Inner", identity))
+	assert.Nil(t, err)
+}
+
+func TestBuildJSONUnmarshalerArgument(t *testing.T) {
+	type TSample struct {
+		Name string `json:"name"`
+	}
+
+	_, err := BuildJSONUnmarshaler(TSample{})
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Only works on pointers to structs: got struct value; pass a pointer like (*modtracker.TSample)(nil)")
+
+	_, err = BuildJSONUnmarshaler(nil)
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Only works on pointers to structs: got nil")
+
+	_, err = BuildJSONUnmarshaler("name")
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Only works on pointers to structs")
+
+	ts := &TSample{Name: "Bob"}
+	unmarshal, err := BuildJSONUnmarshaler(ts)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bob", ts.Name)
+	modified, err := unmarshal([]byte(`{"name": "Alice"}`), ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Equal(t, "Alice", ts.Name)
+}
+
+func TestJSONPointerPaths(t *testing.T) {
+	type Balance struct {
+		Amount int `json:"amount"`
+	}
+	type TSample struct {
+		FirstName *string `json:"firstName"`
+		Inner     struct {
+			Address string `json:"address"`
+		} `json:"inner"`
+		Accounts map[string]Balance `json:"accounts"`
+		Pet      string
+	}
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithJSONPointerPaths())
+	assert.Nil(t, err)
+
+	var ts TSample
+	res, err := unmarshal([]byte(`{"firstName": null, "inner": {"address": "Main St"}, "accounts": {"a/b~c": {"amount": 5}}, "Pet": "cat"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/firstName", "/inner/address", "/accounts/a~1b~0c/amount", "/Pet"}, res.Modified)
+	assert.Equal(t, []string{"/firstName"}, res.Nulled)
+	assert.Equal(t, 5, ts.Accounts["a/b~c"].Amount)
+
+	res, err = unmarshal([]byte(`{"accounts": {"x.y": {"amount": 1}, "p[q": {"amount": 2}, "r].s": {"amount": 3}}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/accounts/x.y/amount", "/accounts/p[q/amount", "/accounts/r].s/amount"}, res.Modified)
+
+	plain, err := BuildJSONUnmarshaler((*TSample)(nil), WithJSONPointerPaths())
+	assert.Nil(t, err)
+	modified, err := plain([]byte(`{"inner": {"address": "Main St"}, "accounts": {}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/inner", "/accounts"}, modified)
+}
+
+func TestPointerToCollection(t *testing.T) {
+	type TSample struct {
+		Tags   *[]string       `json:"tags"`
+		Counts *map[string]int `json:"counts"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"tags": ["a", "b"], "counts": {"x": 1}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Tags", "Counts"}, modified)
+	assert.Equal(t, []string{"a", "b"}, *ts.Tags)
+	assert.Equal(t, map[string]int{"x": 1}, *ts.Counts)
+
+	res, err := UnmarshalJSONDetailed([]byte(`{"tags": null, "counts": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Tags", "Counts"}, res.Modified)
+	assert.Equal(t, []string{"Tags", "Counts"}, res.Cleared)
+	assert.Nil(t, ts.Tags)
+	assert.Nil(t, ts.Counts)
+
+	modified, err = UnmarshalJSON([]byte(`{"tags": []}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Tags"}, modified)
+	assert.NotNil(t, ts.Tags)
+	assert.Equal(t, []string{}, *ts.Tags)
+
+	_, err = UnmarshalJSON([]byte(`{"tags": "a"}`), &ts)
+	assert.NotNil(t, err)
+}
+
+func TestRequiredTag(t *testing.T) {
+	type TSample struct {
+		Name  *string `json:"name" modtracker:"required,alias=fullName"`
+		Email string  `json:"email" modtracker:"required"`
+		Age   int     `json:"age"`
+	}
+
+	var ts TSample
+	res, err := UnmarshalJSONDetailed([]byte(`{"fullName": "Bob", "email": "bob@example.com"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Email"}, res.Modified)
+
+	res, err = UnmarshalJSONDetailed([]byte(`{"name": null, "email": "bob@example.com"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, res.Nulled)
+	assert.Nil(t, ts.Name)
+
+	_, err = UnmarshalJSON([]byte(`{"age": 3}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(err.(errorList)))
+	assert.Contains(t, err.Error(), "Required field Name is missing from JSON")
+	assert.Contains(t, err.Error(), "Required field Email is missing from JSON")
+
+	_, err = UnmarshalJSON([]byte(`{"name": "Bob", "email": null}`), &ts)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "missing")
+}
+
+type tags []string
+
+type items []struct {
+	ID int `json:"id"`
+}
+
+func TestNamedSliceType(t *testing.T) {
+	type TSample struct {
+		Tags     tags   `json:"tags"`
+		Items    *items `json:"items"`
+		Optional tags   `json:"optional"`
+	}
+
+	ts := TSample{Optional: tags{"z"}}
+	res, err := UnmarshalJSONDetailed([]byte(`{"tags": ["x", "y"], "items": [{"id": 1}], "optional": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Tags", "Items", "Optional"}, res.Modified)
+	assert.Equal(t, []string{"Optional"}, res.Cleared)
+	assert.Equal(t, tags{"x", "y"}, ts.Tags)
+	assert.Equal(t, 1, (*ts.Items)[0].ID)
+	assert.Nil(t, ts.Optional)
+}
+
+func TestStats(t *testing.T) {
+	type TSample struct {
+		Name  *string `json:"name" modtracker:"alias=fullName"`
+		Age   int     `json:"age"`
+		Email string  `json:"email"`
+	}
+
+	var stats []Stats
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithStats(func(s Stats) {
+		stats = append(stats, s)
+	}))
+	assert.Nil(t, err)
+
+	data := []byte(`{"name": "Bob", "fullName": "Bob Smith", "age": 42, "other": true}`)
+	var ts TSample
+	_, err = unmarshal(data, &ts)
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"age": "x"}`), &ts)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 2, len(stats))
+	assert.Equal(t, len(data), stats[0].BytesIn)
+	assert.Equal(t, 2, stats[0].FieldsPresent)
+	assert.Equal(t, 3, stats[0].FieldsRegistered)
+	assert.Equal(t, 1, stats[1].FieldsPresent)
+}
+
+type upper string
+
+func (u *upper) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*u = upper(strings.ToUpper(s))
+	return nil
+}
+
+func TestPointerToUnmarshaler(t *testing.T) {
+	type TSample struct {
+		Code  *upper `json:"code"`
+		Value upper  `json:"value"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"code": "usd", "value": "eur"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Code", "Value"}, modified)
+	assert.Equal(t, upper("USD"), *ts.Code)
+	assert.Equal(t, upper("EUR"), ts.Value)
+
+	modified, err = UnmarshalJSON([]byte(`{"code": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Code"}, modified)
+	assert.Nil(t, ts.Code)
+
+	_, err = UnmarshalJSON([]byte(`{"code": 12}`), &ts)
+	assert.NotNil(t, err)
+}
+
+func TestOnlyFields(t *testing.T) {
+	type TSample struct {
+		Name  *string `json:"name"`
+		Role  string  `json:"role" modtracker:"alias=userRole"`
+		Email string  `json:"email"`
+	}
+
+	dec, err := NewDecoder((*TSample)(nil))
+	assert.Nil(t, err)
+	data := []byte(`{"name": "Bob", "userRole": "admin", "email": "bob@example.com"}`)
+
+	var ts TSample
+	modified, err := dec.Decode(data, &ts, WithOnlyFields("Name", "Email"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Email"}, modified)
+	assert.Equal(t, "", ts.Role)
+
+	ts = TSample{}
+	_, err = dec.Decode(data, &ts, WithOnlyFields("Name"), WithRejectOtherFields())
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(err.(errorList)))
+	assert.Contains(t, err.Error(), "Field Role may not be set")
+	assert.Contains(t, err.Error(), "Field Email may not be set")
+
+	ts = TSample{}
+	modified, err = dec.Decode(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Role", "Email"}, modified)
+}
+
+func TestRawMessageField(t *testing.T) {
+	type TSample struct {
+		Details json.RawMessage  `json:"details"`
+		Extra   *json.RawMessage `json:"extra"`
+		Count   json.RawMessage  `json:"count"`
+		Flag    json.RawMessage  `json:"flag"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"details": {"a": [1, 2],  "b": {"c": "d"}}, "extra": "text", "count": 12.5, "flag": false}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Details", "Extra", "Count", "Flag"}, modified)
+	assert.Equal(t, `{"a": [1, 2],  "b": {"c": "d"}}`, string(ts.Details))
+	assert.Equal(t, `"text"`, string(*ts.Extra))
+	assert.Equal(t, `12.5`, string(ts.Count))
+	assert.Equal(t, `false`, string(ts.Flag))
+}
+
+func TestDecoderWithOptions(t *testing.T) {
+	type TSample struct {
+		Age  int    `json:"age"`
+		Name string `json:"name"`
+	}
+
+	strict, err := NewDecoder((*TSample)(nil), WithMaxStringLength(3))
+	assert.Nil(t, err)
+	lenient, err := strict.WithOptions(WithStringlyTyped())
+	assert.Nil(t, err)
+
+	var ts TSample
+	_, err = strict.Decode([]byte(`{"age": "42"}`), &ts)
+	assert.NotNil(t, err)
+
+	modified, err := lenient.Decode([]byte(`{"age": "42"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+	assert.Equal(t, 42, ts.Age)
+
+	_, err = lenient.Decode([]byte(`{"name": "Robert"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exceeds maximum length of 3 bytes")
+
+	//the fields are already discovered, so Options that would discover others are rejected
+	_, err = strict.WithOptions(WithNestedTracking())
+	assert.NotNil(t, err)
+	_, err = strict.Decode([]byte(`{"years": 42}`), &ts, WithAliases(map[string]string{"years": "Age"}))
+	assert.EqualError(t, err, "Options that change which fields are discovered, such as WithAliases or WithNestedTracking, can only be passed when building")
+	_, err = strict.DecodeInto(nil, []byte(`{"age": 42}`), &ts, WithKebabCase())
+	assert.NotNil(t, err)
+
+	//field names are checked just like when building
+	noop := func(reflect.Value) error { return nil }
+	_, err = strict.WithOptions(WithFieldTransform("Nmae", noop))
+	assert.EqualError(t, err, "WithFieldTransform refers to unknown field Nmae")
+	_, err = strict.Decode([]byte(`{"age": 42}`), &ts, WithFieldTransform("Aeg", noop))
+	assert.EqualError(t, err, "WithFieldTransform refers to unknown field Aeg")
+	_, err = strict.DecodeInto(nil, []byte(`{"age": 42}`), &ts, WithFieldTransform("Age", noop))
+	assert.Nil(t, err)
+}
+
+func TestUnknownKeyHandler(t *testing.T) {
+	type TSample struct {
+		Name  string `json:"name" modtracker:"alias=fullName"`
+		Inner struct {
+			City string `json:"city"`
+		} `json:"inner"`
+	}
+
+	var unknown []string
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithUnknownKeyHandler(func(key []byte) {
+		unknown = append(unknown, string(key))
+	}))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"fullName": "Bob", "age": 4, "inner": {"city": "Paris", "zip": "75001"}, "x\"y": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Inner.City"}, modified)
+	assert.Equal(t, []string{"age", `x"y`}, unknown)
+}
+
+func TestNoDecodableFields(t *testing.T) {
+	type TSample struct {
+		name  string
+		Skip  string `json:"-"`
+		Ready chan bool
+		Run   func()
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"name": "Bob", "Skip": "x", "Ready": true}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, modified)
+	assert.Equal(t, TSample{}, ts)
+
+	modified, err = UnmarshalJSON([]byte(`{}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, modified)
+}
+
+func TestLargeUnsigned(t *testing.T) {
+	type TSample struct {
+		Big   uint64  `json:"big"`
+		Small *uint8  `json:"small"`
+		Ptr   *uint64 `json:"ptr"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"big": 18446744073709551615, "small": 255, "ptr": 9223372036854775808}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Big", "Small", "Ptr"}, modified)
+	assert.Equal(t, uint64(math.MaxUint64), ts.Big)
+	assert.Equal(t, uint8(255), *ts.Small)
+	assert.Equal(t, uint64(1<<63), *ts.Ptr)
+
+	_, err = UnmarshalJSON([]byte(`{"big": 18446744073709551616, "small": 256, "ptr": 1.5}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, len(err.(errorList)))
+	assert.Contains(t, err.Error(), "cannot assign 18446744073709551616 to uint64 field Big")
+	assert.Contains(t, err.Error(), "cannot assign 256 to uint8 field Small")
+	assert.Contains(t, err.Error(), "cannot assign 1.5 to uint64 field Ptr")
+}
+
+func TestJSONNumber(t *testing.T) {
+	type Price struct {
+		Amount json.Number  `json:"amount"`
+		Tax    *json.Number `json:"tax"`
+	}
+	type TSample struct {
+		Total json.Number `json:"total"`
+		Price Price       `json:"price"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"total": 12345678901234567890.10, "price": {"amount": 1e-7, "tax": -0.50}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Total", "Price.Amount", "Price.Tax"}, modified)
+	assert.Equal(t, json.Number("12345678901234567890.10"), ts.Total)
+	assert.Equal(t, json.Number("1e-7"), ts.Price.Amount)
+	assert.Equal(t, json.Number("-0.50"), *ts.Price.Tax)
+
+	modified, err = UnmarshalJSON([]byte(`{"price": {"amount": 2.50}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Price"}, modified)
+	assert.Equal(t, json.Number("2.50"), ts.Price.Amount)
+}
+
+func TestFlattenJSON(t *testing.T) {
+	values, paths, err := FlattenJSON([]byte(`{"name": "Bob \"B\"", "inner": {"address": {"city": "Paris", "zip": null}, "tags": ["a", {"b": true}], "none": []}, "age": 42, "meta": {}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "inner.address.city", "inner.address.zip", "inner.tags[0]", "inner.tags[1].b", "inner.none", "age", "meta"}, paths)
+	assert.Equal(t, map[string]interface{}{
+		"name":               `Bob "B"`,
+		"inner.address.city": "Paris",
+		"inner.address.zip":  nil,
+		"inner.tags[0]":      "a",
+		"inner.tags[1].b":    true,
+		"inner.none":         []interface{}{},
+		"age":                float64(42),
+		"meta":               map[string]interface{}{},
+	}, values)
+
+	values, paths, err = FlattenJSON([]byte(` {} `))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(values))
+	assert.Nil(t, paths)
+
+	_, _, err = FlattenJSON([]byte(`[1, 2]`))
+	assert.EqualError(t, err, "Invalid JSON, expected an object, got array")
+
+	_, _, err = FlattenJSON([]byte(`{"a": {"b": 1`))
+	assert.NotNil(t, err)
+}
+
+func TestMaxFields(t *testing.T) {
+	type TSample struct {
+		A        int `json:"a"`
+		B        int `json:"b"`
+		C        int `json:"c"`
+		Accounts map[string]struct {
+			Balance int `json:"balance"`
+		} `json:"accounts"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithMaxFields(3))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"a": 1, "b": 2, "c": 3}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"A", "B", "C"}, modified)
+
+	ts = TSample{}
+	_, err = unmarshal([]byte(`{"a": 1, "b": 2, "c": 3, "accounts": {}}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nJSON sets more than the maximum of 3 fields\n")
+
+	ts = TSample{}
+	_, err = unmarshal([]byte(`{"accounts": {"x": {"balance": 1}, "y": {"balance": 2}, "z": {}, "w": {}, "v": {}}, "a": 1}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nJSON sets more than the maximum of 3 fields\n")
+	assert.Equal(t, 0, ts.A)
+}
+
+type key struct {
+	bytes []byte
+}
+
+func (k *key) UnmarshalBinary(data []byte) error {
+	if len(data) != 4 {
+		return fmt.Errorf("key must be 4 bytes, got %d", len(data))
+	}
+	k.bytes = append([]byte(nil), data...)
+	return nil
+}
+
+func TestBinaryUnmarshaler(t *testing.T) {
+	type TSample struct {
+		Key  key     `json:"key"`
+		Next *key    `json:"next"`
+		Home url.URL `json:"home"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"key": "AQIDBA==", "next": "BQYHCA==", "home": "https://example.com"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Key", "Next", "Home"}, modified)
+	assert.Equal(t, []byte{1, 2, 3, 4}, ts.Key.bytes)
+	assert.Equal(t, []byte{5, 6, 7, 8}, ts.Next.bytes)
+	assert.Equal(t, "example.com", ts.Home.Host)
+
+	_, err = UnmarshalJSON([]byte(`{"key": "AQID", "next": "not base64!"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Invalid value in JSON for field Key: key must be 4 bytes, got 3")
+	assert.Contains(t, err.Error(), "Invalid value in JSON for field Next: illegal base64 data")
+}
+
+func TestProgress(t *testing.T) {
+	type Entry struct {
+		Value int `json:"value"`
+	}
+	type TSample struct {
+		Name    string           `json:"name"`
+		Entries map[string]Entry `json:"entries"`
+	}
+
+	var calls []int
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithProgress(func(n int) {
+		calls = append(calls, n)
+	}))
+	assert.Nil(t, err)
+
+	entries := make([]string, 249)
+	for i := range entries {
+		entries[i] = fmt.Sprintf(`"e%d": {"value": %d}`, i, i)
+	}
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"name": "big", "entries": {`+strings.Join(entries, ",")+`}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, 250, len(modified))
+	assert.Equal(t, []int{100, 200, 251}, calls)
+
+	calls = nil
+	_, err = unmarshal([]byte(`{}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0}, calls)
+}
+
+func TestCaseInsensitiveTag(t *testing.T) {
+	type TSample struct {
+		UserID string `json:"userId" modtracker:"ci,alias=user"`
+		Name   string `json:"name"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"USERID": "u1", "Name": "Bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserID"}, modified)
+	assert.Equal(t, "u1", ts.UserID)
+	assert.Equal(t, "", ts.Name)
+
+	ts = TSample{}
+	modified, err = UnmarshalJSON([]byte(`{"USER": "u2", "name": "Bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserID", "Name"}, modified)
+	assert.Equal(t, "u2", ts.UserID)
+
+	ts = TSample{}
+	modified, err = UnmarshalJSON([]byte(`{"userid": "u3", "userId": "u4"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserID"}, modified)
+	assert.Equal(t, "u4", ts.UserID)
+	ts = TSample{}
+	modified, err = UnmarshalJSON([]byte(`{"userId": "u5", "USERID": "u6"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserID"}, modified)
+	assert.Equal(t, "u5", ts.UserID)
+
+	//keys matched when ignoring case are reported in document order like the others
+	ts = TSample{}
+	modified, err = UnmarshalJSON([]byte(`{"USERID": "u7", "name": "Bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserID", "Name"}, modified)
+}
+
+func TestCaseInsensitiveTagDuplicateKeys(t *testing.T) {
+	_, err := BuildJSONUnmarshaler((*struct {
+		Name  string `json:"name" modtracker:"ci"`
+		Name2 string `json:"NAME" modtracker:"ci"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Duplicate JSON key name when ignoring case for fields Name and Name2")
+
+	//only one of the fields ignores case, so a key that doesn't match exactly can only go to that one
+	type TSample struct {
+		Name  string `json:"name" modtracker:"ci,alias=NAME"`
+		Name2 string `json:"Name"`
+	}
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"nAmE": "x"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+}
+
+func TestCaseInsensitiveTagExtras(t *testing.T) {
+	type TSample struct {
+		UserID string                     `json:"userId" modtracker:"ci"`
+		Extras map[string]json.RawMessage `modtracker:"extras"`
+	}
+
+	var ts TSample
+	_, err := UnmarshalJSON([]byte(`{"USERID": "u1", "other": 1}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"other": json.RawMessage(`1`)}, ts.Extras)
+}
+
+func TestDecodeWithSnapshot(t *testing.T) {
+	type TSample struct {
+		Name  *string `json:"name"`
+		Age   int     `json:"age"`
+		Email string  `json:"email" modtracker:"alias=mail"`
+		Pet   string  `json:"pet"`
+	}
+
+	name := "Bob"
+	ts := TSample{Name: &name, Age: 40, Email: "bob@example.com", Pet: "cat"}
+	before, modified, err := DecodeWithSnapshot([]byte(`{"name": null, "age": 41, "email": "b@example.com", "mail": "c@example.com"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Age", "Email"}, modified)
+	assert.Equal(t, map[string]interface{}{
+		"Name":  &name,
+		"Age":   40,
+		"Email": "bob@example.com",
+	}, before)
+	assert.Nil(t, ts.Name)
+	assert.Equal(t, 41, ts.Age)
+
+	before, modified, err = DecodeWithSnapshot([]byte(`{"age": "x"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Nil(t, before)
+	assert.Nil(t, modified)
+}
+
+type shape interface {
+	Area() float64
+}
+
+type square struct {
+	Side float64 `json:"side"`
+}
+
+func (s square) Area() float64 {
+	return s.Side * s.Side
+}
+
+type circle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c *circle) Area() float64 {
+	return 3 * c.Radius * c.Radius
+}
+
+func TestInterfaceResolver(t *testing.T) {
+	type TSample struct {
+		Shape shape `json:"shape"`
+		Other shape `json:"other"`
+	}
+
+	resolver := WithInterfaceResolver(func(fieldName string, raw []byte) (reflect.Value, error) {
+		kind, err := jsonparser.GetString(raw, "kind")
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		switch kind {
+		case "square":
+			var s square
+			err = json.Unmarshal(raw, &s)
+			return reflect.ValueOf(s), err
+		case "circle":
+			c := &circle{}
+			err = json.Unmarshal(raw, c)
+			return reflect.ValueOf(c), err
+		case "text":
+			return reflect.ValueOf("text"), nil
+		}
+		return reflect.Value{}, fmt.Errorf("unknown kind %s for %s", kind, fieldName)
+	})
+
+	assert.NotNil(t, Check((*TSample)(nil)))
+	assert.Nil(t, Check((*TSample)(nil), resolver))
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), resolver)
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"shape": {"kind": "square", "side": 2}, "other": {"kind": "circle", "radius": 1}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Shape", "Other"}, modified)
+	assert.Equal(t, square{Side: 2}, ts.Shape)
+	assert.Equal(t, float64(3), ts.Other.Area())
+
+	_, err = unmarshal([]byte(`{"shape": {"kind": "text"}, "other": {"kind": "hexagon"}}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Interface resolver returned a value that cannot be assigned to field Shape of type modtracker.shape")
+	assert.Contains(t, err.Error(), "Resolving the type of field Other: unknown kind hexagon for Other")
+}
+
+type timestamps struct {
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	Deleted   *time.Time `json:"deleted"`
+	Published time.Time  `json:"published"`
+	Expires   *time.Time `json:"expires"`
+	Reviewed  time.Time  `json:"reviewed"`
+	Approved  time.Time  `json:"approved"`
+	Archived  *time.Time `json:"archived"`
+}
+
+var timestampsJSON = []byte(`{"created": "2016-01-02T15:04:05Z", "updated": "2016-01-03T15:04:05Z",
+	"deleted": "2016-01-04T15:04:05Z", "published": "2016-01-05T15:04:05+01:00", "expires": "2017-01-02T15:04:05Z",
+	"reviewed": "2016-01-06T15:04:05.123Z", "approved": "2016-01-07T15:04:05Z", "archived": null}`)
+
+func BenchmarkUnmarshalJSONTimes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var s timestamps
+		if _, err := UnmarshalJSON(timestampsJSON, &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStandardJSONUnmarshalTimes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var s timestamps
+		if err := json.Unmarshal(timestampsJSON, &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNumberParser(t *testing.T) {
+	type TSample struct {
+		Amount float64 `json:"amount"`
+		Count  *int32  `json:"count"`
+		Limit  float32 `json:"limit"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNumberParser(func(raw []byte, kind reflect.Kind) (reflect.Value, error) {
+		s := strings.Replace(strings.Replace(string(raw), ".", "", -1), ",", ".", 1)
+		if kind == reflect.Float64 || kind == reflect.Float32 {
+			f, err := strconv.ParseFloat(s, 64)
+			return reflect.ValueOf(f), err
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		return reflect.ValueOf(i), err
+	}))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"amount": "1.234,56", "count": "2.000", "limit": 7.5}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Amount", "Count", "Limit"}, modified)
+	assert.Equal(t, 1234.56, ts.Amount)
+	assert.Equal(t, int32(2000), *ts.Count)
+	assert.Equal(t, float32(7.5), ts.Limit)
+
+	_, err = unmarshal([]byte(`{"count": "abc"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Invalid value in JSON for field Count")
+}
+
+func TestDottedKeys(t *testing.T) {
+	type Inner struct {
+		C int `json:"c"`
+	}
+	type TSample struct {
+		AB    int   `json:"a.b"`
+		A     Inner `json:"a"`
+		Other int   `json:"b"`
+	}
+
+	for _, opts := range [][]Option{nil, {WithNestedTracking()}} {
+		unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), opts...)
+		assert.Nil(t, err)
+		var ts TSample
+		_, err = unmarshal([]byte(`{"a.b": 1, "a": {"b": 2, "c": 3}}`), &ts)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, ts.AB)
+		assert.Equal(t, 3, ts.A.C)
+		assert.Equal(t, 0, ts.Other)
+	}
+
+	values, paths, err := FlattenJSON([]byte(`{"a.b": 1, "a": {"b": 2}, "c[0]": 3, "d\\e": 4}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`a\.b`, "a.b", `c\[0]`, `d\\e`}, paths)
+	assert.Equal(t, float64(1), values[`a\.b`])
+	assert.Equal(t, float64(2), values["a.b"])
+}
+
+func TestRejectTrailingData(t *testing.T) {
+	type TSample struct {
+		Name string `json:"name"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithRejectTrailingData())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(" {\"name\": \"Bob\"} \n\t"), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+
+	ts = TSample{}
+	_, err = unmarshal([]byte(`{"name": "Bob"} {"name": "Alice"}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid JSON, unexpected data after the top-level value at offset 16\n")
+	assert.Equal(t, "", ts.Name)
+
+	modified, err = UnmarshalJSON([]byte(`{"name": "Bob"}garbage`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+}
+
+func TestDeprecationHandler(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+		Zip    string `json:"zip" modtracker:"deprecated"`
+	}
+	type TSample struct {
+		Name     string  `json:"name"`
+		FullName string  `json:"fullName" modtracker:"deprecated"`
+		Address  Address `json:"address"`
+	}
+
+	var deprecated []string
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithDeprecationHandler(func(fieldName string) {
+		deprecated = append(deprecated, fieldName)
+	}))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"name": "Bob", "fullName": "Bob Smith", "address": {"street": "Main St", "zip": "12345"}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "FullName", "Address.Street", "Address.Zip"}, modified)
+	assert.Equal(t, "Bob Smith", ts.FullName)
+	assert.Equal(t, []string{"FullName", "Address.Zip"}, deprecated)
+
+	deprecated = nil
+	_, err = unmarshal([]byte(`{"name": "Bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Nil(t, deprecated)
+}
+
+func TestInlineTag(t *testing.T) {
+	type Meta struct {
+		Version int     `json:"version"`
+		Owner   *string `json:"owner" modtracker:"required"`
+	}
+	type TSample struct {
+		Name string `json:"name"`
+		Meta Meta   `modtracker:",inline"`
+	}
+
+	var ts TSample
+	res, err := UnmarshalJSONDetailed([]byte(`{"name": "doc", "version": 3, "owner": null, "Meta": {"version": 4}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Version", "Owner"}, res.Modified)
+	assert.Equal(t, []string{"Owner"}, res.Nulled)
+	assert.Equal(t, 3, ts.Meta.Version)
+
+	_, err = UnmarshalJSON([]byte(`{"name": "doc"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Required field Owner is missing from JSON")
+
+	type TConflict struct {
+		Version string `json:"v"`
+		Meta    Meta   `modtracker:"inline"`
+	}
+	_, err = BuildJSONUnmarshaler((*TConflict)(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Version of inlined field Meta conflicts with field Version")
+
+	//the conflict doesn't depend on which of the fields is declared first
+	type TConflictAfter struct {
+		Meta    Meta   `modtracker:"inline"`
+		Version string `json:"v"`
+	}
+	_, err = BuildJSONUnmarshaler((*TConflictAfter)(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Version of inlined field Meta conflicts with field Version")
+
+	type TDuplicate struct {
+		Ver  string `json:"version"`
+		Meta Meta   `modtracker:"inline"`
+	}
+	_, err = BuildJSONUnmarshaler((*TDuplicate)(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Duplicate JSON key version for fields Ver and Meta.Version")
+
+	type TPointer struct {
+		Meta *Meta `modtracker:"inline"`
+	}
+	_, err = BuildJSONUnmarshaler((*TPointer)(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Meta tagged as inline must be a struct")
+}
+
+func TestCoerceNumbersToStrings(t *testing.T) {
+	type TSample struct {
+		Zip   string  `json:"zip"`
+		Code  *string `json:"code"`
+		Count int     `json:"count"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithCoerceNumbersToStrings())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"zip": 90210, "code": 1.50e3, "count": 2}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Zip", "Code", "Count"}, modified)
+	assert.Equal(t, "90210", ts.Zip)
+	assert.Equal(t, "1.50e3", *ts.Code)
+
+	_, err = UnmarshalJSON([]byte(`{"zip": 90210}`), &ts)
+	assert.NotNil(t, err)
+}
+
+func TestOneOfTag(t *testing.T) {
+	type TSample struct {
+		Card    *string `json:"card" modtracker:"oneof=payment"`
+		Account *string `json:"account" modtracker:"oneof=payment"`
+		Cash    *bool   `json:"cash" modtracker:"oneof=payment"`
+		Email   *string `json:"email" modtracker:"oneof=contact"`
+		Phone   *string `json:"phone" modtracker:"oneof=contact"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"card": "4111", "phone": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Card", "Phone"}, modified)
+
+	_, err = UnmarshalJSON([]byte(`{"card": "4111", "cash": true}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(err.(errorList)))
+	assert.Contains(t, err.Error(), "Exactly one of fields Card, Account, Cash must be present in JSON, got Card, Cash")
+	assert.Contains(t, err.Error(), "Exactly one of fields Email, Phone must be present in JSON, got none")
+}
+
+func TestFieldValidator(t *testing.T) {
+	type TSample struct {
+		Currency *string `json:"currency"`
+		Amount   *int    `json:"amount"`
+	}
+
+	var seen [][]string
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithFieldValidator(func(fieldName string, v reflect.Value, modifiedSoFar []string) error {
+		seen = append(seen, modifiedSoFar)
+		if fieldName != "Amount" {
+			return nil
+		}
+		if v.IsNil() || v.Elem().Int() < 0 {
+			return fmt.Errorf("amount must be a positive number")
+		}
+		for _, m := range modifiedSoFar {
+			if m == "Currency" {
+				return nil
+			}
+		}
+		return fmt.Errorf("currency must come before amount")
+	}))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"currency": "USD", "amount": 5}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Currency", "Amount"}, modified)
+	assert.Equal(t, [][]string{{}, {"Currency"}}, seen)
+
+	_, err = unmarshal([]byte(`{"amount": 6}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid value for field Amount: currency must come before amount\n")
+	assert.Equal(t, 5, *ts.Amount)
+
+	_, err = unmarshal([]byte(`{"currency": "USD", "amount": null}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid value for field Amount: amount must be a positive number\n")
+	assert.Equal(t, 5, *ts.Amount)
+}
+
+func TestTrimStrings(t *testing.T) {
+	type TSample struct {
+		Name     string  `json:"name"`
+		Nick     *string `json:"nick"`
+		Quoted   string  `json:"quoted,string"`
+		Password string  `json:"password" modtracker:"notrim"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithTrimStrings())
+	assert.Nil(t, err)
+
+	var ts TSample
+	_, err = unmarshal([]byte(`{"name": "  Bob\n", "nick": "\tbobby ", "quoted": "\" x \"", "password": " secret "}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bob", ts.Name)
+	assert.Equal(t, "bobby", *ts.Nick)
+	assert.Equal(t, "x", ts.Quoted)
+	assert.Equal(t, " secret ", ts.Password)
+
+	_, err = UnmarshalJSON([]byte(`{"name": "  Bob "}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, "  Bob ", ts.Name)
+}
+
+func TestUnmarshalJSON5(t *testing.T) {
+	type TSample struct {
+		Name  *string  `json:"name"`
+		Tags  []string `json:"tags"`
+		Inner struct {
+			URL string `json:"url"`
+		} `json:"inner"`
+		Age int `json:"age"`
+	}
+
+	data := []byte(`{
+	// the name of the user
+	"name": "Bob, /* not a comment */ // nor this",
+	/* tags, with
+	   a trailing comma */
+	"tags": ["a", "b",],
+	"inner": {"url": "http://example.com",},
+}`)
+
+	var ts TSample
+	modified, err := UnmarshalJSON5(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Tags", "Inner"}, modified)
+	assert.Equal(t, "Bob, /* not a comment */ // nor this", *ts.Name)
+	assert.Equal(t, []string{"a", "b"}, ts.Tags)
+	assert.Equal(t, "http://example.com", ts.Inner.URL)
+
+	unmarshal, err := BuildJSON5Unmarshaler((*TSample)(nil), WithStringlyTyped())
+	assert.Nil(t, err)
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"age": "3", /* done */}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+	assert.Equal(t, 3, ts.Age)
+
+	_, err = unmarshal([]byte(`{"age": 3 /* oops }`), &ts)
+	assert.EqualError(t, err, "Invalid JSON, unterminated comment at offset 10")
+
+	_, err = UnmarshalJSON5([]byte(`{"age": "x",}`), &ts)
+	assert.NotNil(t, err)
+}
+
+func TestAbsentFieldsUntouched(t *testing.T) {
+	type Address struct {
+		Street string
+		Zip    *string
+	}
+	type TSample struct {
+		Name     string             `json:"name"`
+		Age      *int               `json:"age"`
+		Home     *Address           `json:"home"`
+		Work     Address            `json:"work"`
+		Tags     []string           `json:"tags"`
+		Accounts map[string]Address `json:"accounts"`
+	}
+
+	zip := "12345"
+	age := 40
+	home := &Address{Street: "Main St", Zip: &zip}
+	tags := []string{"a"}
+	accounts := map[string]Address{"x": {Street: "Side St"}}
+	ts := TSample{Name: "Bob", Age: &age, Home: home, Work: Address{Street: "Office Rd", Zip: &zip}, Tags: tags, Accounts: accounts}
+
+	for _, opts := range [][]Option{nil, {WithNestedTracking()}, {WithMergeIntoExisting()}} {
+		unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), opts...)
+		assert.Nil(t, err)
+		modified, err := unmarshal([]byte(`{"name": "Alice"}`), &ts)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Name"}, modified)
+		assert.Equal(t, "Alice", ts.Name)
+		assert.True(t, ts.Age == &age)
+		assert.True(t, ts.Home == home)
+		assert.True(t, ts.Home.Zip == &zip)
+		assert.Equal(t, "Main St", ts.Home.Street)
+		assert.Equal(t, Address{Street: "Office Rd", Zip: &zip}, ts.Work)
+		assert.Equal(t, &tags[0], &ts.Tags[0])
+		assert.Equal(t, reflect.ValueOf(accounts).Pointer(), reflect.ValueOf(ts.Accounts).Pointer())
+		assert.Equal(t, 1, len(ts.Accounts))
+	}
+	assert.Equal(t, 40, age)
+	assert.Equal(t, "12345", zip)
+}
+
+func TestEmptyObject(t *testing.T) {
+	type Inner struct {
+		Address string `json:"address"`
+	}
+	type TSample struct {
+		Inner  *Inner         `json:"inner"`
+		Value  Inner          `json:"value"`
+		Values map[string]int `json:"values"`
+	}
+
+	data := []byte(`{"inner": { }, "value": {}, "values": {}}`)
+	ts := TSample{Inner: &Inner{Address: "Main St"}}
+	res, err := UnmarshalJSONDetailed(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inner", "Value", "Values"}, res.Modified)
+	assert.Equal(t, []string{"Inner", "Value", "Values"}, res.Empty)
+	assert.Nil(t, res.Nulled)
+	assert.Equal(t, &Inner{}, ts.Inner)
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithEmptyObjectAsNull())
+	assert.Nil(t, err)
+	ts = TSample{Inner: &Inner{Address: "Main St"}}
+	res, err = unmarshal(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inner", "Value", "Values"}, res.Modified)
+	assert.Equal(t, []string{"Inner", "Value", "Values"}, res.Empty)
+	assert.Equal(t, []string{"Inner"}, res.Nulled)
+	assert.Equal(t, []string{"Inner"}, res.Cleared)
+	assert.Nil(t, ts.Inner)
+	assert.NotNil(t, ts.Values)
+
+	res, err = unmarshal([]byte(`{"inner": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inner"}, res.Nulled)
+	assert.Nil(t, res.Empty)
+
+	type TScalars struct {
+		Age   *int `json:"age"`
+		Count int  `json:"count"`
+	}
+	scalars, err := BuildDetailedJSONUnmarshaler((*TScalars)(nil), WithEmptyObjectAsNull())
+	assert.Nil(t, err)
+	res, err = scalars([]byte(`{"age": {}, "count": {}}`), &TScalars{})
+	assert.EqualError(t, err, "2 Errors found:\n"+
+		"Invalid type in JSON, expected int for field Age, got Object\n"+
+		"Invalid type in JSON, expected int for field Count, got Object\n")
+	assert.Nil(t, res.Empty)
+
+	res, err = unmarshal([]byte(`{"inner": {"address": "Side St"}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inner"}, res.Modified)
+	assert.Nil(t, res.Nulled)
+	assert.Nil(t, res.Empty)
+	assert.Equal(t, "Side St", ts.Inner.Address)
+}
+
+func TestDashTag(t *testing.T) {
+	type TDash struct {
+		Skipped string `json:"-"`
+		Dash    string `json:"-,"`
+	}
+	var ts, std TDash
+	data := []byte(`{"-": "dash", "Skipped": "x"}`)
+	modified, err := UnmarshalJSON(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Dash"}, modified)
+	assert.Nil(t, json.Unmarshal(data, &std))
+	assert.Equal(t, std, ts)
+	assert.Equal(t, "dash", ts.Dash)
+}
+
+func TestRootPath(t *testing.T) {
+	type TSample struct {
+		Name *string `json:"name"`
+		Age  int     `json:"age"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithRootPath("data"))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"meta": {"name": "ignored"}, "data": {"name": "Bob", "age": 42}, "age": 7}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Age"}, modified)
+	assert.Equal(t, "Bob", *ts.Name)
+	assert.Equal(t, 42, ts.Age)
+
+	_, err = unmarshal([]byte(`{"name": "Bob"}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid JSON, expected an object at data\n")
+
+	deep, err := BuildJSONUnmarshaler((*TSample)(nil), WithRootPath("response", "user"))
+	assert.Nil(t, err)
+	ts = TSample{}
+	modified, err = deep([]byte(`{"response": {"user": {"age": 3}}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+
+	_, err = deep([]byte(`{"response": {"user": [1]}}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid JSON, expected an object at response.user\n")
+}
+
+func TestModifiedByField(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+		Zip    string `json:"zip"`
+	}
+	type TSample struct {
+		Name     string             `json:"name"`
+		Home     Address            `json:"home"`
+		Work     *Address           `json:"work"`
+		Accounts map[string]Address `json:"accounts"`
+	}
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+
+	var ts TSample
+	res, err := unmarshal([]byte(`{"name": "Bob", "home": {"street": "Main St", "zip": "12345"}, "work": {}, "accounts": {"a": {"zip": "1"}}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]string{
+		"Name":     {},
+		"Home":     {"Street", "Zip"},
+		"Work":     {},
+		"Accounts": {"[a].Zip"},
+	}, res.ModifiedByField())
+	assert.Equal(t, map[string][]string{}, Result{}.ModifiedByField())
+}
+
+type money struct {
+	Amount   int
+	Currency string
+}
+
+func (m money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == "USD"
+}
+
+func TestIgnoreZeroValues(t *testing.T) {
+	type TSample struct {
+		Name    string    `json:"name"`
+		Age     int       `json:"age"`
+		Admin   bool      `json:"admin"`
+		Balance money     `json:"balance"`
+		Joined  time.Time `json:"joined"`
+		Nick    *string   `json:"nick"`
+		Tags    []string  `json:"tags"`
+	}
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithIgnoreZeroValues())
+	assert.Nil(t, err)
+
+	ts := TSample{Name: "Bob", Age: 30}
+	res, err := unmarshal([]byte(`{"name": "", "age": 0, "admin": false, "balance": {"Amount": 0, "Currency": "USD"}, "joined": "0001-01-01T00:00:00Z", "nick": "", "tags": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Nick", "Tags"}, res.Modified)
+	assert.Equal(t, []string{"Tags"}, res.Nulled)
+	assert.Equal(t, "Bob", ts.Name)
+	assert.Equal(t, 30, ts.Age)
+
+	ts = TSample{}
+	res, err = unmarshal([]byte(`{"name": "Ann", "balance": {"Amount": 0, "Currency": "EUR"}, "joined": "2020-01-01T00:00:00Z"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Balance", "Joined"}, res.Modified)
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	type Inner struct {
+		ZipCode string `json:"zipCode"`
+	}
+	type TSample struct {
+		UserName string                     `json:"userName"`
+		Email    string                     `modtracker:"alias=e_mail_address"`
+		Home     Inner                      `json:"home"`
+		Extras   map[string]json.RawMessage `modtracker:"extras"`
+	}
+	normalize := func(k string) string {
+		return strings.ToLower(strings.Replace(k, "_", "", -1))
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithKeyNormalizer(normalize), WithNestedTracking())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"user_name": "bob", "EMAILADDRESS": "bob@example.com", "home": {"ZIP_CODE": "12345"}, "other": 1}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserName", "Email", "Home.ZipCode"}, modified)
+	assert.Equal(t, TSample{UserName: "bob", Email: "bob@example.com", Home: Inner{ZipCode: "12345"}, Extras: map[string]json.RawMessage{"other": json.RawMessage("1")}}, ts)
+
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"UserName": "ann", "user_name": "bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"UserName"}, modified)
+	assert.Equal(t, "bob", ts.UserName)
+
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithKeyNormalizer(normalize), WithAliases(map[string]string{"User_Name": "Email"}))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Duplicate JSON key username for fields UserName and Email")
+}
+
+func TestMalformedJSONMatchingEveryKey(t *testing.T) {
+	type TSample struct {
+		A string `json:"A"`
+		B string `json:"B"`
+	}
+	type TFolded struct {
+		A string `json:"A" modtracker:"ci"`
+		B string `json:"B"`
+	}
+
+	data := []byte(`{"A":"x", "B":}`)
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	_, err = unmarshal(data, &TSample{})
+	assert.NotNil(t, err)
+
+	//the keys are matched one by one when they are normalized, folded, or counted, and the JSON is still checked
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithKeyNormalizer(strings.ToLower))
+	assert.Nil(t, err)
+	_, err = unmarshal(data, &TSample{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Invalid JSON")
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithDuplicateKeyHandler(func(string, int) {}))
+	assert.Nil(t, err)
+	_, err = unmarshal(data, &TSample{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Invalid JSON")
+
+	_, err = UnmarshalJSON(data, &TFolded{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Invalid JSON")
+}
+
+func TestDecodeBatch(t *testing.T) {
+	type TSample struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	newT := func() interface{} { return &TSample{} }
+
+	items := make([][]byte, 50)
+	for i := range items {
+		items[i] = []byte(fmt.Sprintf(`{"name": "user%d", "age": %d}`, i, i))
+	}
+	items[7] = []byte(`{"age": "seven"}`)
+
+	res, err := DecodeBatch(items, newT, 4)
+	assert.Nil(t, err)
+	assert.Len(t, res, len(items))
+	for i, r := range res {
+		if i == 7 {
+			assert.NotNil(t, r.Err)
+			continue
+		}
+		assert.Nil(t, r.Err)
+		assert.Equal(t, []string{"Name", "Age"}, r.Modified)
+		assert.Equal(t, &TSample{Name: fmt.Sprintf("user%d", i), Age: i}, r.Value)
+	}
+
+	res, err = DecodeBatch(nil, newT, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, res)
+
+	_, err = DecodeBatch(items, func() interface{} { return TSample{} }, 4)
+	assert.NotNil(t, err)
+}
+
+func TestRawMessagePointer(t *testing.T) {
+	type TSample struct {
+		Name    string           `json:"name"`
+		Payload *json.RawMessage `json:"payload"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"payload": {"a": [1, 2], "b": "c"}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Payload"}, modified)
+	if assert.NotNil(t, ts.Payload) {
+		assert.Equal(t, `{"a": [1, 2], "b": "c"}`, string(*ts.Payload))
+	}
+
+	modified, err = unmarshal([]byte(`{"payload": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Payload"}, modified)
+	assert.Nil(t, ts.Payload)
+
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"name": "Bob"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Nil(t, ts.Payload)
+}
+
+func TestPointerFieldTypeMismatch(t *testing.T) {
+	type TSample struct {
+		Int    *int                `json:"int"`
+		Uint   *uint               `json:"uint"`
+		Float  *float64            `json:"float"`
+		Bool   *bool               `json:"bool"`
+		String *string             `json:"string"`
+		Time   *time.Time          `json:"time"`
+		Struct *struct{ A string } `json:"struct"`
+		Slice  *[]int              `json:"slice"`
+	}
+	values := map[string]string{
+		"string":  `"abc"`,
+		"number":  `12`,
+		"boolean": `true`,
+		"object":  `{"a": 1}`,
+		"array":   `[1, 2]`,
+	}
+	accepts := map[string][]string{
+		"int":    {"number"},
+		"uint":   {"number"},
+		"float":  {"number"},
+		"bool":   {"boolean"},
+		"string": {"string"},
+		"time":   {"number"},
+		"struct": {"object"},
+		"slice":  {"array"},
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	for key, ok := range accepts {
+		for vtName, value := range values {
+			accepted := false
+			for _, v := range ok {
+				accepted = accepted || v == vtName
+			}
+			if accepted {
+				continue
+			}
+			var ts TSample
+			assert.NotPanics(t, func() {
+				_, err = unmarshal([]byte(fmt.Sprintf(`{%q: %s}`, key, value)), &ts)
+			}, "%s receiving %s", key, vtName)
+			if assert.NotNil(t, err, "%s receiving %s", key, vtName) {
+				assert.Contains(t, err.Error(), "field "+strings.Title(key), "%s receiving %s", key, vtName)
+			}
+			assert.Equal(t, TSample{}, ts, "%s receiving %s", key, vtName)
+		}
+	}
+}
+
+func TestMinMaxTags(t *testing.T) {
+	type TSample struct {
+		Discount int      `json:"discount" modtracker:"min=0,max=100"`
+		Rate     *float64 `json:"rate" modtracker:"max=1.5"`
+		Count    uint     `json:"count" modtracker:"min=1"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"discount": 100, "rate": 1.5, "count": 1}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Discount", "Rate", "Count"}, modified)
+
+	modified, err = unmarshal([]byte(`{"discount": 101, "rate": 1.6, "count": 0}`), &ts)
+	assert.EqualError(t, err, "3 Errors found:\n"+
+		"Invalid value in JSON, 101 is greater than the maximum of 100 for field Discount\n"+
+		"Invalid value in JSON, 1.6 is greater than the maximum of 1.5 for field Rate\n"+
+		"Invalid value in JSON, 0 is less than the minimum of 1 for field Count\n")
+	assert.Nil(t, modified)
+
+	assert.Equal(t, 100, ts.Discount)
+	assert.Equal(t, 1.5, *ts.Rate)
+	assert.Equal(t, uint(1), ts.Count)
+
+	_, err = unmarshal([]byte(`{"discount": -1, "rate": null}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid value in JSON, -1 is less than the minimum of 0 for field Discount\n")
+	assert.Equal(t, 100, ts.Discount)
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		Name string `modtracker:"max=10"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Name tagged with max must be a number")
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		Age int `modtracker:"min=ten"`
+	})(nil))
+	assert.EqualError(t, err, `Failure during UnmarshalJSON: Invalid min "ten" for field Age`)
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		Age int `modtracker:"min=10,max=1"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Invalid range for field Age, min is greater than max")
+}
+
+func TestTimeLayoutAndLocation(t *testing.T) {
+	type TSample struct {
+		Start time.Time  `json:"start"`
+		End   *time.Time `json:"end"`
+	}
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithTimeLayout("2006-01-02 15:04"), WithTimeLocation(ny))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"start": "2020-01-15 09:30", "end": "2020-07-15 09:30"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Start", "End"}, modified)
+	assert.Equal(t, "2020-01-15T09:30:00-05:00", ts.Start.Format(time.RFC3339))
+	assert.Equal(t, "2020-07-15T09:30:00-04:00", ts.End.Format(time.RFC3339))
+	assert.Equal(t, ny, ts.Start.Location())
+
+	_, err = unmarshal([]byte(`{"start": "2020-01-15T09:30:00Z"}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nInvalid value in JSON, cannot parse \"2020-01-15T09:30:00Z\" as a time with layout 2006-01-02 15:04 for field Start\n")
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithTimeLocation(ny))
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"start": "2020-01-15T09:30:00Z"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-01-15T09:30:00Z", ts.Start.Format(time.RFC3339))
+}
+
+func TestRequireModifiable(t *testing.T) {
+	_, err := BuildJSONUnmarshaler((*Sample)(nil), WithRequireModifiable())
+	assert.Nil(t, err)
+
+	type TSample struct {
+		Name string `json:"name"`
+	}
+	_, err = BuildJSONUnmarshaler((*TSample)(nil), WithRequireModifiable())
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Type modtracker.TSample doesn't implement Modifiable: add a GetModified() []string method")
+
+	_, err = NewDecoder((*TSample)(nil), WithRequireModifiable())
+	assert.NotNil(t, err)
+
+	_, err = BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+}
+
+func TestMaxSliceLength(t *testing.T) {
+	type TSample struct {
+		IDs   []int     `json:"ids"`
+		Tags  *[]string `json:"tags" modtracker:"maxlen=2"`
+		Pairs [3]int    `json:"pairs"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithMaxSliceLength(4))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"ids": [1, 2, 3, 4], "tags": ["a", "b"], "pairs": [1, 2, 3]}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"IDs", "Tags", "Pairs"}, modified)
+
+	ts = TSample{}
+	_, err = unmarshal([]byte(`{"ids": [1, 2, 3, 4, 5], "tags": ["a", "b", "c"], "pairs": [1, 2, 3, 4, 5, 6]}`), &ts)
+	assert.EqualError(t, err, "3 Errors found:\n"+
+		"Array for field IDs has 5 elements, more than the maximum of 4\n"+
+		"Array for field Tags has 3 elements, more than the maximum of 2\n"+
+		"Array for field Pairs has 6 elements, more than the maximum of 4\n")
+	assert.Equal(t, TSample{}, ts)
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"ids": [1, 2, 3, 4, 5], "tags": ["a", "b", "c"]}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nArray for field Tags has 3 elements, more than the maximum of 2\n")
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		Name string `modtracker:"maxlen=2"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Name tagged with maxlen must be a slice or array")
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		IDs []int `modtracker:"maxlen=0"`
+	})(nil))
+	assert.EqualError(t, err, `Failure during UnmarshalJSON: Invalid maxlen "0" for field IDs`)
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithMaxSliceLength(4), WithSliceAppend())
+	assert.Nil(t, err)
+	ts = TSample{IDs: []int{1, 2}}
+	_, err = unmarshal([]byte(`{"ids": [3, 4]}`), &ts)
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"ids": [5]}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nAppending to field IDs gives 5 elements, more than the maximum of 4\n")
+	assert.Equal(t, []int{1, 2, 3, 4}, ts.IDs)
+}
+
+func TestOtherPackageUnexportedFields(t *testing.T) {
+	unmarshal, err := BuildJSONUnmarshaler((*testtypes.Customer)(nil))
+	assert.Nil(t, err)
+	assert.Nil(t, Check((*testtypes.Customer)(nil)))
+
+	var c testtypes.Customer
+	var modified []string
+	assert.NotPanics(t, func() {
+		modified, err = unmarshal([]byte(`{"id": "1", "name": "Bob", "email": "bob@example.com", "secret": "s", "Note": "hi", "createdBy": "admin"}`), &c)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ID", "Email", "Note"}, modified)
+	assert.Equal(t, "1", c.ID)
+	assert.Equal(t, "bob@example.com", c.Email)
+	assert.Equal(t, "hi", *c.Note)
+	assert.Equal(t, "", c.Name())
+	assert.Equal(t, "", c.Secret())
+}
+
+func TestMergeModified(t *testing.T) {
+	assert.Equal(t, []string{"Name", "Inner.A", "Inner.B", "Accounts[a].Balance", "Accounts[b]", "Age"}, MergeModified(
+		[]string{"Name", "Inner.A", "Accounts[a].Balance"},
+		[]string{"Inner.B", "Name", "Age"},
+		nil,
+		[]string{"Accounts[b]", "Inner.A"},
+	))
+	assert.Equal(t, []string{"A", "B"}, MergeModified([]string{"A", "B", "A"}))
+	assert.Nil(t, MergeModified())
+}
+
+func TestBuildUnmarshalerFromSchema(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": ["integer", "null"]},
+			"score": {"type": "number"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"meta": {}
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`)
+	unmarshal, err := BuildUnmarshalerFromSchema(schema)
+	assert.Nil(t, err)
+
+	m := map[string]interface{}{"age": 3.0, "score": 1.0}
+	modified, err := unmarshal([]byte(`{"name": "Bob", "age": null, "tags": ["a"], "meta": {"x": 1}}`), m)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "age", "tags", "meta"}, modified)
+	assert.Equal(t, map[string]interface{}{
+		"name":  "Bob",
+		"age":   nil,
+		"score": 1.0,
+		"tags":  []interface{}{"a"},
+		"meta":  map[string]interface{}{"x": 1.0},
+	}, m)
+
+	var pm map[string]interface{}
+	modified, err = unmarshal([]byte(`{"name": "Ann", "age": 4.0}`), &pm)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "age"}, modified)
+	assert.Equal(t, map[string]interface{}{"name": "Ann", "age": 4.0}, pm)
+
+	m = map[string]interface{}{}
+	modified, err = unmarshal([]byte(`{"age": 4.5, "score": "high", "other": true}`), m)
+	assert.EqualError(t, err, "4 Errors found:\n"+
+		"Invalid type in JSON, expected integer or null for property age, got number\n"+
+		"Invalid type in JSON, expected number for property score, got string\n"+
+		"Property other is not allowed by the schema\n"+
+		"Required property name is missing from JSON\n")
+	assert.Nil(t, modified)
+	assert.Empty(t, m)
+
+	_, err = unmarshal([]byte(`{"name": "Bob"}`), map[string]string{})
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: target must be a non-nil map[string]interface{}, got map[string]string")
+
+	_, err = BuildUnmarshalerFromSchema([]byte(`{"type": "array"}`))
+	assert.EqualError(t, err, "Invalid JSON Schema, expected type object, got array")
+
+	_, err = BuildUnmarshalerFromSchema([]byte(`{"properties": {"a": {"type": "text"}}}`))
+	assert.EqualError(t, err, "Invalid JSON Schema, unknown type text for property a")
+}
+
+func TestByteOrderMark(t *testing.T) {
+	bom := "\xef\xbb\xbf"
+	var ts Sample
+	modified, err := UnmarshalJSON([]byte(bom+" \r\n\t{\"FirstName\": \"Bob\", \"Age\": 3}\n"), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"FirstName", "Age"}, modified)
+
+	unmarshal, err := BuildJSONUnmarshaler((*Sample)(nil), WithRejectTrailingData(), WithRootPath("data"))
+	assert.Nil(t, err)
+	ts = Sample{}
+	modified, err = unmarshal([]byte(bom+`{"data": {"Age": 4}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+	assert.Equal(t, 4, *ts.Age)
+
+	d, err := NewDecoder((*Sample)(nil))
+	assert.Nil(t, err)
+	modified, err = d.DecodeReader(strings.NewReader(bom+`{"Age": 5}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+
+	_, paths, err := FlattenJSON([]byte(bom + `{"a": 1}`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, paths)
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+	}
+	type TSample struct {
+		Name     string             `json:"name"`
+		Home     *Address           `json:"home"`
+		Accounts map[string]Address `json:"accounts"`
+		Raw      map[string]string  `json:"raw"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithDisallowUnknownFields(), WithNestedTracking())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"name": "Bob", "home": {"street": "Main St"}, "accounts": {"a": {"street": "x"}}, "raw": {"any": "key"}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Home.Street", "Accounts[a].Street", "Raw"}, modified)
+
+	_, err = unmarshal([]byte(`{"name": "Bob", "age": 3, "home": {"street": "Main St", "color": "red"}, "accounts": {"a": {"zip": "1"}}}`), &ts)
+	assert.EqualError(t, err, "3 Errors found:\n"+
+		"Unknown field Home.color in JSON\n"+
+		"Unknown field Accounts[a].zip in JSON\n"+
+		"Unknown field age in JSON\n")
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithDisallowUnknownFields())
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"home": {"street": "Main St", "color": "red"}}`), &ts)
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"age": 3}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nUnknown field age in JSON\n")
+}
+
+func TestNumberRange(t *testing.T) {
+	type TSample struct {
+		Small int8    `json:"small"`
+		Ratio float32 `json:"ratio"`
+		Count uint64  `json:"count"`
+	}
+
+	var ts TSample
+	modified, err := UnmarshalJSON([]byte(`{"small": -128, "ratio": 1.5e3, "count": 18446744073709551615}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Small", "Ratio", "Count"}, modified)
+	assert.Equal(t, TSample{Small: -128, Ratio: 1500, Count: math.MaxUint64}, ts)
+
+	_, err = UnmarshalJSON([]byte(`{"small": 128, "ratio": 1e39, "count": 18446744073709551616}`), &ts)
+	assert.EqualError(t, err, "3 Errors found:\n"+
+		"Invalid value in JSON, cannot convert \"128\" to int8 for field Small\n"+
+		"Invalid value in JSON, cannot convert \"1e39\" to float32 for field Ratio\n"+
+		"Invalid value in JSON, cannot assign 18446744073709551616 to uint64 field Count\n")
+}
+
+func TestLenientNumberSyntax(t *testing.T) {
+	type TSample struct {
+		Count  int      `json:"count"`
+		Size   *uint8   `json:"size"`
+		Ratio  float64  `json:"ratio"`
+		Offset int      `json:"offset"`
+		Note   string   `json:"note"`
+		Values []string `json:"values"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithLenientNumberSyntax())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"count": +5, "size": 05, "ratio": +1.5e+2, "offset": -007, "note": "a +5 b", "values": ["+1"]}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Size", "Ratio", "Offset", "Note", "Values"}, modified)
+	assert.Equal(t, 5, ts.Count)
+	assert.Equal(t, uint8(5), *ts.Size)
+	assert.Equal(t, 150.0, ts.Ratio)
+	assert.Equal(t, -7, ts.Offset)
+	assert.Equal(t, "a +5 b", ts.Note)
+	assert.Equal(t, []string{"+1"}, ts.Values)
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"count": 5, "ratio": 0.5, "offset": 0}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Ratio", "Offset"}, modified)
+	assert.Equal(t, TSample{Count: 5, Ratio: 0.5}, ts)
+
+	_, err = unmarshal([]byte(`{"count": 05, "size": 300, "offset": 1.5}`), &ts)
+	assert.EqualError(t, err, "3 Errors found:\n"+
+		"Invalid value in JSON, number 05 for field Count has a leading zero\n"+
+		"Invalid value in JSON, cannot assign 300 to uint8 field Size\n"+
+		"Invalid value in JSON, cannot convert \"1.5\" to int for field Offset\n")
+
+	_, err = unmarshal([]byte(`{"count": +5}`), &ts)
+	assert.NotNil(t, err)
+
+	type TInner struct {
+		Size int             `json:"size"`
+		Raw  json.RawMessage `modtracker:"raw"`
+	}
+	type TNested struct {
+		Count  int                        `json:"count"`
+		Inner  TInner                     `json:"inner"`
+		Extras map[string]json.RawMessage `modtracker:"extras"`
+	}
+	detailed, err := BuildDetailedJSONUnmarshaler((*TNested)(nil), WithLenientNumberSyntax(), WithNestedTracking())
+	assert.Nil(t, err)
+	var tn TNested
+	res, err := detailed([]byte(`{"count": +5, "inner": {"size": +2}, "other": +3}`), &tn)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, tn.Count)
+	assert.Equal(t, 2, tn.Inner.Size)
+	assert.Equal(t, json.RawMessage(`{"size": +2}`), tn.Inner.Raw)
+	assert.Equal(t, map[string]json.RawMessage{"other": json.RawMessage(`+3`)}, tn.Extras)
+	assert.Equal(t, []Change{{Field: "Count", Raw: json.RawMessage(`+5`)}, {Field: "Inner.Size", Raw: json.RawMessage(`+2`)}}, res.Changes)
+}
+
+func TestBuildErrorsCollected(t *testing.T) {
+	type Inner struct {
+		Code string `modtracker:"max=3"`
+	}
+	type TSample struct {
+		Name    string            `json:"name"`
+		Other   string            `modtracker:"alias=name"`
+		Present map[string]int    `modtracker:"presence"`
+		Tags    string            `modtracker:"maxlen=2"`
+		Age     int               `modtracker:"min=x"`
+		Inner   Inner             `json:"inner"`
+		Extra   map[string]string `modtracker:"extras"`
+	}
+
+	_, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: 6 Errors found:\n"+
+		"Duplicate JSON key name for fields Name and Other\n"+
+		"Field Present tagged as presence must be of type map[string]bool or map[string]struct{}\n"+
+		"Field Tags tagged with maxlen must be a slice or array\n"+
+		"Invalid min \"x\" for field Age\n"+
+		"Field Inner: Field Code tagged with max must be a number\n"+
+		"Field Extra tagged as extras must be of type map[string]json.RawMessage\n")
+
+	err = Check((*TSample)(nil), WithNestedTracking())
+	assert.Len(t, err, 6)
+
+	_, err = BuildJSONUnmarshaler(TSample{})
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Only works on pointers to structs: got struct value; pass a pointer like (*modtracker.TSample)(nil)")
+}
+
+func TestInterfaceResolverPointer(t *testing.T) {
+	type TSample struct {
+		Shape *shape `json:"shape"`
+	}
+
+	resolver := WithInterfaceResolver(func(fieldName string, raw []byte) (reflect.Value, error) {
+		if _, _, _, err := jsonparser.Get(raw, "radius"); err == nil {
+			c := &circle{}
+			err = json.Unmarshal(raw, c)
+			return reflect.ValueOf(c), err
+		}
+		var s square
+		err := json.Unmarshal(raw, &s)
+		return reflect.ValueOf(s), err
+	})
+
+	assert.NotNil(t, Check((*TSample)(nil)))
+	assert.Nil(t, Check((*TSample)(nil), resolver))
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), resolver)
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"shape": {"radius": 1}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Shape"}, modified)
+	if assert.NotNil(t, ts.Shape) {
+		assert.Equal(t, &circle{Radius: 1}, *ts.Shape)
+	}
+
+	modified, err = unmarshal([]byte(`{"shape": {"side": 2}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Shape"}, modified)
+	if assert.NotNil(t, ts.Shape) {
+		assert.Equal(t, square{Side: 2}, *ts.Shape)
+	}
+
+	modified, err = unmarshal([]byte(`{"shape": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Shape"}, modified)
+	assert.Nil(t, ts.Shape)
+}
+
+func TestDecoderDecodeInto(t *testing.T) {
+	d, err := NewDecoder((*Sample)(nil))
+	assert.Nil(t, err)
+
+	buf := make([]string, 0, 8)
+	var ts Sample
+	modified, err := d.DecodeInto(buf, []byte(`{"FirstName": "Bob", "Age": 3}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"FirstName", "Age"}, modified)
+	assert.Equal(t, &buf[:1][0], &modified[0])
+
+	modified, err = d.DecodeInto(modified, []byte(`{"Pet": "cat"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Pet"}, modified)
+	assert.Equal(t, &buf[:1][0], &modified[0])
+
+	modified, err = d.DecodeInto(modified, []byte(`{"Age": "x"}`), &ts)
+	assert.NotNil(t, err)
+	assert.Len(t, modified, 0)
+	assert.Equal(t, 8, cap(modified))
+
+	modified, err = d.DecodeInto(nil, []byte(`{"Pet": "dog"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Pet"}, modified)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = d.DecodeInto(buf, []byte(`{"Pet": "cat"}`), &ts)
+	})
+	assert.True(t, allocs < testing.AllocsPerRun(100, func() {
+		_, _ = d.Decode([]byte(`{"Pet": "cat"}`), &ts)
+	}))
+}
+
+func TestRawTag(t *testing.T) {
+	type Inner struct {
+		City string `json:"city"`
+		Raw  []byte `modtracker:"raw"`
+	}
+	type TSample struct {
+		Name  string          `json:"name"`
+		Inner Inner           `json:"inner"`
+		Raw   json.RawMessage `modtracker:"raw"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+
+	data := []byte(`{"name": "Bob", "inner": {"city": "Paris"}}`)
+	var ts TSample
+	modified, err := unmarshal(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Inner.City"}, modified)
+	assert.Equal(t, json.RawMessage(data), ts.Raw)
+	assert.Equal(t, []byte(`{"city": "Paris"}`), ts.Inner.Raw)
+	data[2] = 'N'
+	assert.Equal(t, byte('n'), ts.Raw[2])
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		Raw string `modtracker:"raw"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Raw tagged as raw must be of type []byte or json.RawMessage")
+
+	_, err = BuildJSONUnmarshaler((*struct {
+		Raw []myByte `modtracker:"raw"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: Field Raw tagged as raw must be of type []byte or json.RawMessage")
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithRootPath("data"), WithLenientNumberSyntax())
+	assert.Nil(t, err)
+	data = []byte("\xEF\xBB\xBF{\"data\": {\"name\": \"Ann\"}, \"count\": +1}")
+	ts = TSample{}
+	modified, err = unmarshal(data, &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Equal(t, json.RawMessage(data), ts.Raw)
+
+	type Audit struct {
+		By      string                     `json:"by"`
+		Raw     []byte                     `modtracker:"raw"`
+		Present map[string]bool            `modtracker:"presence"`
+		Extras  map[string]json.RawMessage `modtracker:"extras"`
+	}
+	_, err = BuildJSONUnmarshaler((*struct {
+		Name  string `json:"name"`
+		Audit Audit  `modtracker:"inline"`
+	})(nil))
+	assert.EqualError(t, err, "Failure during UnmarshalJSON: 3 Errors found:\n"+
+		"Field Present of inlined field Audit cannot be tagged as presence\n"+
+		"Field Extras of inlined field Audit cannot be tagged as extras\n"+
+		"Field Raw of inlined field Audit cannot be tagged as raw\n")
+}
+
+func TestValueInterceptor(t *testing.T) {
+	type TSample struct {
+		Name  string `json:"name"`
+		Score int    `json:"score"`
+		Note  *string
+	}
+
+	var ts TSample
+	var seen []string
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithValueInterceptor(func(fieldName string, vt jsonparser.ValueType, raw []byte) (bool, error) {
+		seen = append(seen, fieldName+":"+vt.String())
+		switch {
+		case fieldName == "Score" && vt == jsonparser.String:
+			if string(raw) == "high" {
+				ts.Score = 100
+				return true, nil
+			}
+			return false, fmt.Errorf("unknown score %s", raw)
+		case fieldName == "Note" && vt == jsonparser.Null:
+			return true, nil
+		}
+		return false, nil
+	}))
+	assert.Nil(t, err)
+
+	res, err := unmarshal([]byte(`{"name": "Bob", "score": "high", "Note": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Score", "Note"}, res)
+	assert.Equal(t, []string{"Name:string", "Score:string", "Note:null"}, seen)
+	assert.Equal(t, TSample{Name: "Bob", Score: 100}, ts)
+
+	ts = TSample{}
+	res, err = unmarshal([]byte(`{"score": 5}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Score"}, res)
+	assert.Equal(t, 5, ts.Score)
+
+	_, err = unmarshal([]byte(`{"score": "low"}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nIntercepting field Score: unknown score low\n")
+}
+
+func TestByteSlices(t *testing.T) {
+	type blob []byte
+	type TSample struct {
+		Data  []byte  `json:"data"`
+		Blob  blob    `json:"blob"`
+		Ptr   *[]byte `json:"ptr"`
+		Empty []uint8 `json:"empty"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"data": "aGVsbG8=", "blob": [104, 101, 108, 108, 111], "ptr": [0, 255], "empty": []}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Data", "Blob", "Ptr", "Empty"}, modified)
+	assert.Equal(t, []byte("hello"), ts.Data)
+	assert.Equal(t, blob("hello"), ts.Blob)
+	assert.Equal(t, &[]byte{0, 255}, ts.Ptr)
+	assert.Equal(t, []uint8{}, ts.Empty)
+
+	modified, err = unmarshal([]byte(`{"data": [104, 101], "blob": "aGk=", "ptr": "AQI="}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Data", "Blob", "Ptr"}, modified)
+	assert.Equal(t, []byte("he"), ts.Data)
+	assert.Equal(t, blob("hi"), ts.Blob)
+	assert.Equal(t, &[]byte{1, 2}, ts.Ptr)
+
+	ts = TSample{}
+	_, err = unmarshal([]byte(`{"data": [1, 256, -1, "a"], "blob": "not base64!"}`), &ts)
+	assert.EqualError(t, err, "4 Errors found:\n"+
+		"Invalid value in JSON, expected a number from 0 to 255 for element 1 of field Data, got 256\n"+
+		"Invalid value in JSON, expected a number from 0 to 255 for element 2 of field Data, got -1\n"+
+		"Invalid value in JSON, expected a number from 0 to 255 for element 3 of field Data, got a\n"+
+		"Invalid value in JSON, expected base64 for field Blob: illegal base64 data at input byte 3\n")
+	assert.Equal(t, TSample{}, ts)
+}
+
+type status int
+
+const (
+	statusPending status = iota + 1
+	statusActive
+)
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum(reflect.TypeOf(status(0)), map[string]int64{"PENDING": 1, "ACTIVE": 2})
+	type TSample struct {
+		Status   status  `json:"status"`
+		Previous *status `json:"previous"`
+		Count    int     `json:"count"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"status": "ACTIVE", "previous": 1}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Status", "Previous"}, modified)
+	assert.Equal(t, statusActive, ts.Status)
+	assert.Equal(t, statusPending, *ts.Previous)
+
+	modified, err = unmarshal([]byte(`{"previous": "ACTIVE"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Previous"}, modified)
+	assert.Equal(t, statusActive, *ts.Previous)
+
+	_, err = unmarshal([]byte(`{"status": "DELETED", "count": "3"}`), &ts)
+	assert.EqualError(t, err, "2 Errors found:\n"+
+		"Invalid value in JSON, unknown modtracker.status value \"DELETED\" for field Status\n"+
+		"Invalid type in JSON, expected int for field Count, got String\n")
+
+	assert.Panics(t, func() {
+		RegisterEnum(reflect.TypeOf(""), map[string]int64{"A": 1})
+	})
+}
+
+func TestUnmarshalerTargetType(t *testing.T) {
+	type Base struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type User struct {
+		Base
+		Email string `json:"email"`
+	}
+	type Other struct {
+		ID string `json:"id"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*Base)(nil))
+	assert.Nil(t, err)
+
+	var u User
+	modified, err := unmarshal([]byte(`{"id": "1", "name": "Bob", "email": "bob@example.com"}`), &u)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ID", "Name"}, modified)
+	assert.Equal(t, User{Base: Base{ID: "1", Name: "Bob"}}, u)
+
+	var o Other
+	_, err = unmarshal([]byte(`{"id": "1"}`), &o)
+	assert.EqualError(t, err, "Unmarshaler for modtracker.Base cannot decode into *modtracker.Other")
+	_, err = unmarshal([]byte(`{"id": "1"}`), Base{})
+	assert.EqualError(t, err, "Unmarshaler for modtracker.Base cannot decode into modtracker.Base")
+	_, err = unmarshal([]byte(`{"id": "1"}`), (*Base)(nil))
+	assert.EqualError(t, err, "Unmarshaler for modtracker.Base cannot decode into *modtracker.Base")
+	//a Decoder accepts the same targets
+	dec, err := NewDecoder((*Base)(nil))
+	assert.Nil(t, err)
+	u = User{}
+	modified, err = dec.Decode([]byte(`{"id": "2", "email": "bob@example.com"}`), &u)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ID"}, modified)
+	assert.Equal(t, User{Base: Base{ID: "2"}}, u)
+	modified, err = dec.DecodeInto(modified, []byte(`{"name": "Bob"}`), &u)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Equal(t, "Bob", u.Name)
+	modified, err = dec.DecodeInto(modified, []byte(`{"id": "1"}`), &o)
+	assert.EqualError(t, err, "Unmarshaler for modtracker.Base cannot decode into *modtracker.Other")
+	assert.Empty(t, modified)
+}
+
+func TestDuplicateKeyHandler(t *testing.T) {
+	type Inner struct {
+		Zip string `json:"zip"`
+	}
+	type TSample struct {
+		Name  string `json:"name" modtracker:"alias=fullName"`
+		Age   int    `json:"age"`
+		Inner Inner  `json:"inner"`
+	}
+
+	counts := map[string]int{}
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking(), WithDuplicateKeyHandler(func(fieldName string, count int) {
+		counts[fieldName] = count
+	}))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"name": "a", "age": 1, "fullName": "b", "inner": {"zip": "1", "zip": "2"}, "name": "c"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Age", "Inner.Zip"}, modified)
+	assert.Equal(t, map[string]int{"Name": 3, "Inner.Zip": 2}, counts)
+	assert.Equal(t, TSample{Name: "c", Age: 1, Inner: Inner{Zip: "2"}}, ts)
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"name": "a", "name": "c"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Equal(t, "c", ts.Name)
+
+	//the last value wins whichever options are used
+	for _, opt := range []Option{WithKeyNormalizer(strings.ToLower), WithDuplicateKeyHandler(func(string, int) {})} {
+		unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), opt)
+		assert.Nil(t, err)
+		ts = TSample{}
+		_, err = unmarshal([]byte(`{"name": "a", "name": "c"}`), &ts)
+		assert.Nil(t, err)
+		assert.Equal(t, "c", ts.Name)
+	}
+}
+
+type myInt int
+type myByte byte
+type myString string
+
+func TestNamedPrimitivePointers(t *testing.T) {
+	type TSample struct {
+		Count *myInt    `json:"count"`
+		Label *myString `json:"label"`
+		Plain myInt     `json:"plain"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"count": 42, "label": "hello", "plain": 7}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Label", "Plain"}, modified)
+	if assert.NotNil(t, ts.Count) && assert.NotNil(t, ts.Label) {
+		assert.Equal(t, myInt(42), *ts.Count)
+		assert.Equal(t, myString("hello"), *ts.Label)
+	}
+	assert.Equal(t, myInt(7), ts.Plain)
+
+	res, err := UnmarshalJSONDetailed([]byte(`{"count": null, "label": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Count", "Label"}, res.Modified)
+	assert.Equal(t, []string{"Count", "Label"}, res.Cleared)
+	assert.Nil(t, ts.Count)
+	assert.Nil(t, ts.Label)
+
+	_, err = unmarshal([]byte(`{"count": "42", "label": 5}`), &ts)
+	assert.EqualError(t, err, "2 Errors found:\n"+
+		"Invalid type in JSON, expected modtracker.myInt for field Count, got String\n"+
+		"Invalid type in JSON, expected modtracker.myString for field Label, got Number\n")
+}
+
+func TestSliceAppend(t *testing.T) {
+	type TSample struct {
+		Tags  []string `json:"tags"`
+		IDs   []int    `json:"ids"`
+		Other *[]int   `json:"other"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithSliceAppend())
+	assert.Nil(t, err)
+
+	ts := TSample{Tags: []string{"a"}, Other: &[]int{1}}
+	modified, err := unmarshal([]byte(`{"tags": ["b", "c"], "ids": [1], "other": [2]}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Tags", "IDs", "Other"}, modified)
+	assert.Equal(t, []string{"a", "b", "c"}, ts.Tags)
+	assert.Equal(t, []int{1}, ts.IDs)
+	assert.Equal(t, &[]int{2}, ts.Other)
+
+	_, err = unmarshal([]byte(`{"tags": [], "ids": [2, 3]}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ts.Tags)
+	assert.Equal(t, []int{1, 2, 3}, ts.IDs)
+
+	_, err = unmarshal([]byte(`{"tags": null}`), &ts)
+	assert.Nil(t, err)
+	assert.Nil(t, ts.Tags)
+}
+
+func TestKebabCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"FirstName":  "first-name",
+		"ID":         "id",
+		"UserID":     "user-id",
+		"HTTPServer": "http-server",
+		"Address2":   "address2",
+		"V2Name":     "v2-name",
+		"name":       "name",
+	} {
+		assert.Equal(t, expected, kebabCase(name), name)
+	}
+
+	type TSample struct {
+		FirstName string
+		UserID    int
+		Email     string `json:"e_mail"`
+		Nick      string `json:",omitempty"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithKebabCase())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"first-name": "Bob", "user-id": 3, "e_mail": "bob@example.com", "nick": "b", "FirstName": "x"}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"FirstName", "UserID", "Email", "Nick"}, modified)
+	assert.Equal(t, TSample{FirstName: "Bob", UserID: 3, Email: "bob@example.com", Nick: "b"}, ts)
+}
+
+func TestVersion(t *testing.T) {
+	type TSample struct {
+		Name string `json:"name" modtracker:"v1=name,v2=fullName"`
+		Age  int    `json:"age"`
+	}
+
+	data := []byte(`{"name": "Bob", "fullName": "Bob Smith", "age": 30}`)
+
+	for version, expected := range map[int]string{0: "Bob", 1: "Bob", 2: "Bob Smith", 3: "Bob"} {
+		unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithVersion(version))
+		assert.Nil(t, err)
+
+		var ts TSample
+		modified, err := unmarshal(data, &ts)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Name", "Age"}, modified)
+		assert.Equal(t, TSample{Name: expected, Age: 30}, ts, "version %d", version)
+	}
+}
+
+func TestPartialResults(t *testing.T) {
+	type TInner struct {
+		City string `json:"city"`
+	}
+	type TSample struct {
+		Name    string `json:"name"`
+		Address TInner `json:"address"`
+		Age     int    `json:"age"`
+	}
+
+	data := []byte(`{"name": "Bob", "address": {"city": 12}, "age": 30}`)
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+	var ts TSample
+	modified, err := unmarshal(data, &ts)
+	assert.NotNil(t, err)
+	assert.Nil(t, modified)
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithPartialResults())
+	assert.Nil(t, err)
+	ts = TSample{}
+	modified, err = unmarshal(data, &ts)
+	assert.Contains(t, fmt.Sprint(err), "field Address")
+	assert.Equal(t, []string{"Name", "Age"}, modified)
+	assert.Equal(t, TSample{Name: "Bob", Age: 30}, ts)
+
+	d, err := NewDecoder(&TSample{}, WithPartialResults())
+	assert.Nil(t, err)
+	dst := make([]string, 0, 4)
+	ts = TSample{}
+	modified, err = d.DecodeInto(dst, data, &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"Name", "Age"}, modified)
+
+	modified, err = d.DecodeInto(dst, data, TSample{})
+	assert.NotNil(t, err)
+	assert.Empty(t, modified)
+}
+
+type figure interface {
+	Perimeter() float64
+}
+
+type disc struct {
+	Kind   string  `json:"kind"`
+	Radius float64 `json:"radius"`
+}
+
+func (d disc) Perimeter() float64 {
+	return 6 * d.Radius
+}
+
+type tile struct {
+	Side float64 `json:"side"`
+}
+
+func (t *tile) Perimeter() float64 {
+	return 4 * t.Side
+}
+
+func TestRegisterPolymorphic(t *testing.T) {
+	figureType := reflect.TypeOf((*figure)(nil)).Elem()
+	RegisterPolymorphic(figureType, "kind", map[string]reflect.Type{
+		"disc": reflect.TypeOf(disc{}),
+		"tile": reflect.TypeOf(&tile{}),
+	})
+
+	type TSample struct {
+		Name  string  `json:"name"`
+		Shape figure  `json:"shape"`
+		Other *figure `json:"other"`
+	}
+
+	assert.Nil(t, Check((*TSample)(nil)))
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"name": "a", "shape": {"kind": "disc", "radius": 2}, "other": {"kind": "tile", "side": 3}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Shape", "Other"}, modified)
+	assert.Equal(t, disc{Kind: "disc", Radius: 2}, ts.Shape)
+	if assert.NotNil(t, ts.Other) {
+		assert.Equal(t, &tile{Side: 3}, *ts.Other)
+	}
+
+	_, err = unmarshal([]byte(`{"shape": {"kind": "triangle"}}`), &ts)
+	assert.EqualError(t, err, `1 Errors found:
+Invalid value in JSON, unknown kind "triangle" for field Shape
+`)
+	_, err = unmarshal([]byte(`{"shape": {"radius": 2}}`), &ts)
+	assert.EqualError(t, err, `1 Errors found:
+Invalid value in JSON, expected a string kind in the object for field Shape
+`)
+	_, err = unmarshal([]byte(`{"shape": {"kind": "disc", "radius": "big"}}`), &ts)
+	assert.NotNil(t, err)
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+	ts = TSample{}
+	modified, err = unmarshal([]byte(`{"shape": {"kind": "disc", "radius": 2}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Shape.Kind", "Shape.Radius"}, modified)
+	assert.Equal(t, disc{Kind: "disc", Radius: 2}, ts.Shape)
+
+	assert.Panics(t, func() {
+		RegisterPolymorphic(reflect.TypeOf(disc{}), "kind", nil)
+	})
+	assert.Panics(t, func() {
+		RegisterPolymorphic(figureType, "kind", map[string]reflect.Type{"tile": reflect.TypeOf(tile{})})
+	})
+}
+
+type emblem interface {
+	Perimeter() float64
+}
+
+type badgeCorner struct {
+	Radius int `json:"radius"`
+}
+
+type badge struct {
+	OuterEdge float64
+	Corner    badgeCorner
+}
+
+func (b badge) Perimeter() float64 {
+	return 4 * b.OuterEdge
+}
+
+func TestRegisterPolymorphicOptions(t *testing.T) {
+	RegisterPolymorphic(reflect.TypeOf((*emblem)(nil)).Elem(), "kind", map[string]reflect.Type{"badge": reflect.TypeOf(badge{})})
+
+	type TSample struct {
+		Name   string
+		Emblem emblem `modtracker:"alias=logo"`
+	}
+
+	unmarshal, err := BuildJSONUnmarshaler((*TSample)(nil), WithKebabCase(), WithNestedTracking(), WithDisallowUnknownFields())
+	assert.Nil(t, err)
+
+	var ts TSample
+	modified, err := unmarshal([]byte(`{"emblem": {"kind": "badge", "outer-edge": 2, "corner": {"radius": 1}}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Emblem.OuterEdge", "Emblem.Corner.Radius"}, modified)
+	assert.Equal(t, badge{OuterEdge: 2, Corner: badgeCorner{Radius: 1}}, ts.Emblem)
+
+	modified, err = unmarshal([]byte(`{"emblem": {"kind": "badge", "outer-edge": 2}, "logo": {"kind": "badge", "outer-edge": 3}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Emblem.OuterEdge"}, modified)
+	assert.Equal(t, badge{OuterEdge: 3}, ts.Emblem)
+
+	_, err = unmarshal([]byte(`{"emblem": {"kind": "badge", "inner-edge": 2}}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nUnknown field Emblem.inner-edge in JSON\n")
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithKebabCase(), WithNestedTracking(), WithMaxFields(2))
+	assert.Nil(t, err)
+	_, err = unmarshal([]byte(`{"name": "a", "emblem": {"kind": "badge", "outer-edge": 2, "corner": {"radius": 1}}}`), &ts)
+	assert.EqualError(t, err, "1 Errors found:\nJSON sets more than the maximum of 2 fields\n")
+
+	unmarshal, err = BuildJSONUnmarshaler((*TSample)(nil), WithKebabCase(), WithMaxFields(2))
+	assert.Nil(t, err)
+	modified, err = unmarshal([]byte(`{"name": "a", "emblem": {"kind": "badge", "outer-edge": 2, "corner": {"radius": 1}}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Emblem"}, modified)
+	assert.Equal(t, badge{OuterEdge: 2, Corner: badgeCorner{Radius: 1}}, ts.Emblem)
+
+	//the field maps of the concrete types belong to the Decoder, so they go away with it
+	for i := 0; i < 3; i++ {
+		d, err := NewDecoder((*TSample)(nil), WithKebabCase())
+		assert.Nil(t, err)
+		_, err = d.Decode([]byte(`{"emblem": {"kind": "badge", "outer-edge": 2}}`), &ts)
+		assert.Nil(t, err)
+		_, err = d.Decode([]byte(`{"emblem": {"kind": "badge", "outer-edge": 3}}`), &ts)
+		assert.Nil(t, err)
+		count := 0
+		d.fm.concrete.Range(func(interface{}, interface{}) bool {
+			count++
+			return true
+		})
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestResultChanges(t *testing.T) {
+	type TSample struct {
+		Name  string   `json:"name"`
+		Price float64  `json:"price"`
+		Note  *string  `json:"note"`
+		Tags  []string `json:"tags"`
+	}
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	res, err := unmarshal([]byte(`{"name": "café", "price": 37.50, "note": null, "tags": ["a", "b"]}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, 37.5, ts.Price)
+	assert.Equal(t, []Change{
+		{Field: "Name", Raw: json.RawMessage(`"café"`)},
+		{Field: "Price", Raw: json.RawMessage(`37.50`)},
+		{Field: "Note", Raw: json.RawMessage(`null`)},
+		{Field: "Tags", Raw: json.RawMessage(`["a", "b"]`)},
+	}, res.Changes)
+
+	res, err = UnmarshalJSONDetailed([]byte(`{"price": 1e2}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []Change{{Field: "Price", Raw: json.RawMessage(`1e2`)}}, res.Changes)
+
+	type TInner struct {
+		Zip string `json:"zip"`
+	}
+	type TNested struct {
+		Name  string `json:"name"`
+		Inner TInner `json:"inner"`
+	}
+	data := []byte(`{"inner": {"zip": "12345"}, "name": "Bob"}`)
+	unmarshal, err = BuildDetailedJSONUnmarshaler((*TNested)(nil), WithNestedTracking(), WithModifiedOrder(DeclarationOrder))
+	assert.Nil(t, err)
+	res, err = unmarshal(data, &TNested{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Inner.Zip"}, res.Modified)
+	assert.Equal(t, []Change{
+		{Field: "Name", Raw: json.RawMessage(`"Bob"`)},
+		{Field: "Inner.Zip", Raw: json.RawMessage(`"12345"`)},
+	}, res.Changes)
+
+	unmarshal, err = BuildDetailedJSONUnmarshaler((*TNested)(nil), WithNestedTracking(), WithJSONPointerPaths())
+	assert.Nil(t, err)
+	res, err = unmarshal(data, &TNested{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/inner/zip", "/name"}, res.Modified)
+	assert.Equal(t, []Change{
+		{Field: "/inner/zip", Raw: json.RawMessage(`"12345"`)},
+		{Field: "/name", Raw: json.RawMessage(`"Bob"`)},
+	}, res.Changes)
+}
+
+func TestBuildAll(t *testing.T) {
+	type TFirst struct {
+		Name string `json:"name"`
+	}
+	type TSecond struct {
+		Age int `json:"age"`
+	}
+	type TBad struct {
+		A string `json:"a"`
+		B string `json:"b" modtracker:"alias=a"`
+	}
+
+	unmarshalers, err := BuildAll((*TFirst)(nil), (*TSecond)(nil))
+	assert.Nil(t, err)
+	assert.Len(t, unmarshalers, 2)
+
+	var first TFirst
+	modified, err := unmarshalers[reflect.TypeOf(TFirst{})]([]byte(`{"name": "Bob"}`), &first)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name"}, modified)
+	assert.Equal(t, "Bob", first.Name)
+
+	var second TSecond
+	modified, err = unmarshalers[reflect.TypeOf(TSecond{})]([]byte(`{"age": 3}`), &second)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Age"}, modified)
+	assert.Equal(t, 3, second.Age)
+
+	_, err = BuildAll((*TFirst)(nil), (*TBad)(nil), TSecond{})
+	assert.EqualError(t, err, `2 Errors found:
+Failure during UnmarshalJSON of *modtracker.TBad: Duplicate JSON key a for fields A and B
+Failure during UnmarshalJSON of modtracker.TSecond: Only works on pointers to structs: got struct value; pass a pointer like (*modtracker.TSecond)(nil)
+`)
+}
+
+func TestResultSetNonNull(t *testing.T) {
+	type TInner struct {
+		Zip *string `json:"zip"`
+	}
+	type TSample struct {
+		Name     string  `json:"name"`
+		Nickname *string `json:"nickname"`
+		Age      *int    `json:"age"`
+		Email    *string `json:"email"`
+		Inner    *TInner `json:"inner"`
+	}
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil))
+	assert.Nil(t, err)
+
+	var ts TSample
+	res, err := unmarshal([]byte(`{"name": "Bob", "nickname": "B", "age": null, "inner": {"zip": "12345"}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Name", "Nickname", "Age", "Inner"}, res.Modified)
+	assert.Equal(t, []string{"Nickname", "Inner"}, res.SetNonNull)
+
+	unmarshal, err = BuildDetailedJSONUnmarshaler((*TSample)(nil), WithNestedTracking())
+	assert.Nil(t, err)
+	res, err = unmarshal([]byte(`{"email": "b@example.com", "inner": {"zip": "12345"}}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Email", "Inner", "Inner.Zip"}, res.SetNonNull)
+	assert.Equal(t, []string{"Email", "Inner.Zip"}, res.Modified)
+
+	//fields populated by an unmarshaling function or a value interceptor are reported too
+	nickname := func(data []byte, vt jsonparser.ValueType, target reflect.Value) error {
+		s := string(data)
+		target.Set(reflect.ValueOf(&s))
+		return nil
+	}
+	unmarshal, err = BuildDetailedJSONUnmarshaler((*TSample)(nil), WithUnmarshalerFor("Nickname", nickname),
+		WithValueInterceptor(func(n string, vt jsonparser.ValueType, value []byte) (bool, error) {
+			return n == "Email", nil
+		}))
+	assert.Nil(t, err)
+	res, err = unmarshal([]byte(`{"nickname": "B", "email": "b@example.com", "age": 30}`), &ts)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Nickname", "Email", "Age"}, res.SetNonNull)
+}
+
+func TestPartialResultsRejectedFields(t *testing.T) {
+	type TSample struct {
+		Name   string  `json:"name"`
+		Count  int     `json:"count" modtracker:"max=10"`
+		Amount *int    `json:"amount"`
+		Note   *string `json:"note"`
+	}
+
+	unmarshal, err := BuildDetailedJSONUnmarshaler((*TSample)(nil), WithPartialResults(),
+		WithFieldValidator(func(fieldName string, v reflect.Value, _ []string) error {
+			if fieldName == "Amount" && v.IsNil() {
+				return fmt.Errorf("amount is required")
+			}
+			return nil
+		}))
+	assert.Nil(t, err)
+
+	amount := 5
+	ts := TSample{Count: 3, Amount: &amount}
+	res, err := unmarshal([]byte(`{"name": "Bob", "count": 50, "amount": null, "note": null}`), &ts)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"Name", "Note"}, res.Modified)
+	assert.Equal(t, []string{"Note"}, res.Nulled)
+	assert.Empty(t, res.Cleared)
+	assert.Equal(t, TSample{Name: "Bob", Count: 3, Amount: &amount}, ts)
+
+	//a struct or map whose fields are tracked individually is rejected as a whole when one of its fields is
+	type TInner struct {
+		Zip  int    `json:"zip"`
+		City string `json:"city"`
+	}
+	type TNested struct {
+		Name     string             `json:"name"`
+		Inner    *TInner            `json:"inner"`
+		Home     TInner             `json:"home"`
+		Accounts map[string]*TInner `json:"accounts"`
+	}
+	detailed, err := BuildDetailedJSONUnmarshaler((*TNested)(nil), WithNestedTracking(), WithPartialResults())
+	assert.Nil(t, err)
+	var tn TNested
+	res, err = detailed([]byte(`{"name": "Bob", "inner": {"city": "Paris", "zip": "bad"}, "home": {"zip": "bad"}, `+
+		`"accounts": {"a": {"zip": 1}, "b": {"zip": "bad"}}}`), &tn)
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"Name"}, res.Modified)
+	assert.Empty(t, res.SetNonNull)
+	assert.Equal(t, TNested{Name: "Bob"}, tn)
+
+	detailed, err = BuildDetailedJSONUnmarshaler((*TNested)(nil), WithNestedTracking(), WithPartialResults(), WithMergeIntoExisting())
+	assert.Nil(t, err)
+	tn = TNested{Inner: &TInner{Zip: 1}, Home: TInner{City: "Rome"}}
+	inner := tn.Inner
+	res, err = detailed([]byte(`{"inner": {"city": "Paris", "zip": "bad"}, "home": {"city": "Paris", "zip": "bad"}}`), &tn)
+	assert.NotNil(t, err)
+	assert.Empty(t, res.Modified)
+	assert.Equal(t, TNested{Inner: &TInner{Zip: 1}, Home: TInner{City: "Rome"}}, tn)
+
+	res, err = detailed([]byte(`{"inner": {"city": "Paris"}}`), &tn)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Inner.City"}, res.Modified)
+	assert.True(t, inner == tn.Inner)
+	assert.Equal(t, TInner{Zip: 1, City: "Paris"}, *tn.Inner)
+}
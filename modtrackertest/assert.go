@@ -0,0 +1,64 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+// Package modtrackertest provides helpers for testing code that uses modtracker.
+package modtrackertest
+
+import (
+	"github.com/capitalone/modtracker"
+	"sort"
+	"strings"
+)
+
+// TestingT is the subset of *testing.T used by the helpers in this package, so the package doesn't depend on the
+// testing package.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertModified verifies that m reports exactly the expected modified fields, in any order. If it doesn't, it fails
+// the test with the fields that are missing and the fields that are unexpected. It returns whether the assertion
+// succeeded.
+func AssertModified(t TestingT, m modtracker.Modifiable, expected ...string) bool {
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	counts := map[string]int{}
+	for _, v := range m.GetModified() {
+		counts[v]++
+	}
+	for _, v := range expected {
+		counts[v]--
+	}
+	var missing, unexpected []string
+	for k, v := range counts {
+		for ; v < 0; v++ {
+			missing = append(missing, k)
+		}
+		for ; v > 0; v-- {
+			unexpected = append(unexpected, k)
+		}
+	}
+	if missing == nil && unexpected == nil {
+		return true
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	t.Errorf("Modified fields don't match:\n\tmissing:    [%s]\n\tunexpected: [%s]", strings.Join(missing, ", "), strings.Join(unexpected, ", "))
+	return false
+}
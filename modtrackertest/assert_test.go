@@ -0,0 +1,49 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtrackertest
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type modified []string
+
+func (m modified) GetModified() []string {
+	return m
+}
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertModified(t *testing.T) {
+	r := &recorder{}
+	assert.True(t, AssertModified(r, modified{"Name", "Age", "Inner.Address"}, "Inner.Address", "Name", "Age"))
+	assert.True(t, AssertModified(r, modified{}))
+	assert.Nil(t, r.errors)
+
+	assert.False(t, AssertModified(r, modified{"Name", "Age", "Age"}, "Name", "Email"))
+	assert.Equal(t, []string{"Modified fields don't match:\n\tmissing:    [Email]\n\tunexpected: [Age, Age]"}, r.errors)
+
+	AssertModified(t, modified{"Name"}, "Name")
+}
@@ -0,0 +1,261 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"encoding/json"
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+var (
+	modifiableType = reflect.TypeOf((*Modifiable)(nil)).Elem()
+)
+
+// buildNestedStruct determines how the fields of a struct nested in another struct are tracked. Structs that unmarshal
+// themselves and implement Modifiable report their own modified fields. Other structs without a custom unmarshaler
+// get a field map of their own. Any other type isn't tracked and is unmarshaled as a whole.
+func buildNestedStruct(t reflect.Type, o *options, built map[reflect.Type]*fieldMap) (*fieldMap, bool, error) {
+	pt := reflect.PtrTo(t)
+	if pt.Implements(modifiableType) && pt.Implements(unmarshalerType) {
+		return nil, true, nil
+	}
+	if t.Kind() != reflect.Struct || t == urlType || pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return nil, false, nil
+	}
+	if fm, ok := built[t]; ok {
+		return fm, false, nil
+	}
+	fm, err := buildStructFieldMap(t, o, built)
+	return fm, false, err
+}
+
+// buildNestedMapElem returns the description of the value type of a map when the values are tracked structs or
+// pointers to tracked structs, or nil otherwise.
+func buildNestedMapElem(t reflect.Type, o *options, built map[reflect.Type]*fieldMap) (*fieldValue, error) {
+	it := t
+	if t.Kind() == reflect.Ptr {
+		it = t.Elem()
+	}
+	if it.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	child, tracked, err := buildNestedStruct(it, o, built)
+	if err != nil || (child == nil && !tracked) {
+		return nil, err
+	}
+	return &fieldValue{
+		t:            t,
+		kind:         t.Kind(),
+		internalType: it,
+		internalKind: it.Kind(),
+		child:        child,
+		tracked:      tracked,
+	}, nil
+}
+
+// findField reports whether path names a field of fm, either by its Go name or, with WithNestedTracking, by its path,
+// such as Inner.Address or Accounts[key].Balance, and returns the field. Any path is accepted below a struct that
+// implements Modifiable or an interface, since their fields aren't known until the JSON is decoded, and the returned
+// field is nil for them. A map entry such as Accounts[key] isn't populated as a field, so it isn't accepted.
+func (fm *fieldMap) findField(path string) (*fieldValue, bool) {
+	top := topLevelField(path)
+	rest := path[len(top):]
+	for i := range fm.values {
+		fv := &fm.values[i]
+		if fv.name != top {
+			continue
+		}
+		switch {
+		case rest == "":
+			return fv, true
+		case rest[0] == '.':
+			if fv.tracked || fv.internalKind == reflect.Interface {
+				return nil, true
+			}
+			if fv.child != nil {
+				return fv.child.findField(rest[1:])
+			}
+		case rest[0] == '[' && fv.elem != nil:
+			//the key can contain any character, so each closing bracket is tried in turn
+			for j := strings.IndexByte(rest, ']'); j != -1; j = nextIndex(rest, ']', j) {
+				after := rest[j+1:]
+				if after == "" || after[0] != '.' {
+					continue
+				}
+				if fv.elem.tracked {
+					return nil, true
+				}
+				if f, ok := fv.elem.child.findField(after[1:]); ok {
+					return f, true
+				}
+			}
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// tracksFields reports whether the fields of fv are populated and reported one by one with WithNestedTracking instead
+// of fv being assigned as a whole.
+func (fv *fieldValue) tracksFields() bool {
+	return fv.child != nil || fv.tracked || fv.elem != nil
+}
+
+// nextIndex returns the index of the first instance of c in s after the index i, or -1 if there is none.
+func nextIndex(s string, c byte, i int) int {
+	if j := strings.IndexByte(s[i+1:], c); j != -1 {
+		return i + 1 + j
+	}
+	return -1
+}
+
+// nested populates a struct field, or a map of structs, whose fields are tracked individually. The modified fields are
+// recorded as paths below n, such as Inner.Address or Accounts[key].Balance.
+func (d *decodeState) nested(fValue *fieldValue, value []byte, target reflect.Value, n string, first bool) bool {
+	if fValue.elem != nil {
+		return d.nestedMap(fValue, value, target, n)
+	}
+	//the struct is decoded into a copy, so the field keeps its previous value if a nested field is rejected
+	p := reflect.New(fValue.internalType)
+	merged := d.o.mergeIntoExisting && (fValue.kind != reflect.Ptr || !target.IsNil())
+	if merged {
+		p.Elem().Set(reflect.Indirect(target))
+	}
+	mark := d.mark()
+	//the pointer is recorded ahead of the nested fields, like it is in the JSON
+	if d.detailed && first && fValue.kind == reflect.Ptr {
+		d.res.SetNonNull = append(d.res.SetNonNull, n)
+	}
+	if !d.nestedValue(fValue, value, p.Elem(), n) {
+		d.reset(mark)
+		return false
+	}
+	switch {
+	case fValue.kind != reflect.Ptr:
+		target.Set(p.Elem())
+	case merged:
+		//an existing struct is updated in place, so everything pointing to it sees the new values
+		target.Elem().Set(p.Elem())
+	default:
+		target.Set(p)
+	}
+	return true
+}
+
+func (d *decodeState) nestedMap(fValue *fieldValue, value []byte, target reflect.Value, n string) bool {
+	//the entries are added to a new map, so the field keeps its previous value if an entry is rejected
+	m := reflect.MakeMap(fValue.t)
+	if d.o.mergeIntoExisting && !target.IsNil() {
+		for _, k := range target.MapKeys() {
+			m.SetMapIndex(k, target.MapIndex(k))
+		}
+	}
+	elem := fValue.elem
+	before := len(d.res.Modified)
+	mark := d.mark()
+	errs := len(d.el)
+	i := 0
+	parent := d.loc
+	err := jsonparser.ObjectEach(value, func(key []byte, value []byte, vt jsonparser.ValueType, _ int) error {
+		if d.overLimit() {
+			return nil
+		}
+		en := n + "[" + string(key) + "]"
+		if d.locs != nil {
+			d.loc = d.locate(en, i, string(key))
+			defer func() { d.loc = parent }()
+		}
+		i++
+		kv := reflect.ValueOf(string(key)).Convert(fValue.t.Key())
+		switch vt {
+		case jsonparser.Null:
+			if d.detailed {
+				d.res.Nulled = append(d.res.Nulled, en)
+				if ev := m.MapIndex(kv); ev.IsValid() && elem.kind == reflect.Ptr && !ev.IsNil() {
+					d.res.Cleared = append(d.res.Cleared, en)
+				}
+			}
+			m.SetMapIndex(kv, reflect.Zero(elem.t))
+			d.res.Modified = append(d.res.Modified, en)
+		case jsonparser.Object:
+			p := reflect.New(elem.internalType)
+			ev := m.MapIndex(kv)
+			merged := d.o.mergeIntoExisting && ev.IsValid() && (elem.kind != reflect.Ptr || !ev.IsNil())
+			if merged {
+				p.Elem().Set(reflect.Indirect(ev))
+			}
+			if !d.nestedValue(elem, value, p.Elem(), en) {
+				return nil
+			}
+			switch {
+			case elem.kind != reflect.Ptr:
+				m.SetMapIndex(kv, p.Elem())
+			case merged:
+				ev.Elem().Set(p.Elem())
+			default:
+				m.SetMapIndex(kv, p)
+			}
+		default:
+			d.fail(en, errors.Errorf("Invalid type in JSON, expected %s for field %s, got %s", elem.t, en, vt))
+		}
+		return nil
+	})
+	if err != nil {
+		d.fail(n, errors.Wrap(err, "JSON unmarshaling"))
+	}
+	if len(d.el) > errs {
+		d.reset(mark)
+		return false
+	}
+	target.Set(m)
+	if len(d.res.Modified) == before {
+		d.res.Modified = append(d.res.Modified, n)
+	}
+	return true
+}
+
+// nestedValue populates the addressable struct in dst from a JSON object and returns false if any of the nested fields
+// can't be populated. If none of the nested fields are modified, n itself is recorded as modified.
+func (d *decodeState) nestedValue(fValue *fieldValue, value []byte, dst reflect.Value, n string) bool {
+	before := len(d.res.Modified)
+	errs := len(d.el)
+	if fValue.tracked {
+		p := dst.Addr().Interface()
+		if err := json.Unmarshal(value, p); err != nil {
+			d.fail(n, errors.Wrap(err, "JSON unmarshaling"))
+			return false
+		}
+		for i, v := range p.(Modifiable).GetModified() {
+			if d.locs != nil {
+				d.locate(n+"."+v, i, v)
+			}
+			d.res.Modified = append(d.res.Modified, n+"."+v)
+		}
+	} else {
+		d.object(fValue.child, value, dst, n+".")
+	}
+	if len(d.el) > errs {
+		return false
+	}
+	if len(d.res.Modified) == before {
+		d.res.Modified = append(d.res.Modified, n)
+	}
+	return true
+}
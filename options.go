@@ -0,0 +1,527 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"reflect"
+	"time"
+)
+
+// A FieldUnmarshaler populates a single struct field from a JSON value. It receives the raw bytes of the value, the
+// type of the JSON value, and the settable struct field. For JSON strings, the bytes are the still escaped contents of
+// the string without the surrounding quotes.
+type FieldUnmarshaler func([]byte, jsonparser.ValueType, reflect.Value) error
+
+// An Option changes the behavior of an Unmarshaler created by BuildJSONUnmarshaler.
+type Option func(*options)
+
+type options struct {
+	mergeIntoExisting  bool
+	maxStringLength    int
+	aliases            map[string]string
+	nestedTracking     bool
+	fieldUnmarshalers  map[string]FieldUnmarshaler
+	stringlyTyped      bool
+	errorCounter       func(string)
+	funcResolver       map[string]interface{}
+	unixTimeUnit       time.Duration
+	modifiedOrder      ModifiedOrder
+	looseBooleans      bool
+	fieldTransforms    map[string]func(reflect.Value) error
+	jsonPointerPaths   bool
+	stats              func(Stats)
+	onlyFields         map[string]bool
+	rejectOtherFields  bool
+	unknownKeyHandler  func([]byte)
+	maxFields          int
+	progress           func(int)
+	interfaceResolver  func(string, []byte) (reflect.Value, error)
+	numberParser       func([]byte, reflect.Kind) (reflect.Value, error)
+	rejectTrailingData bool
+	deprecationHandler func(string)
+	coerceNumbers      bool
+	fieldValidator     func(string, reflect.Value, []string) error
+	trimStrings        bool
+	emptyObjectAsNull  bool
+	rootPath           []string
+	ignoreZeroValues   bool
+	keyNormalizer      func(string) string
+	timeLayout         string
+	timeLocation       *time.Location
+	requireModifiable  bool
+	maxSliceLength     int
+	disallowUnknown    bool
+	lenientNumbers     bool
+	valueInterceptor   func(string, jsonparser.ValueType, []byte) (bool, error)
+	duplicateKeys      func(string, int)
+	sliceAppend        bool
+	kebabCase          bool
+	version            int
+	partialResults     bool
+	base               *options //options extended by with, if any
+}
+
+func buildOptions(opts []Option) *options {
+	o := &options{
+		unixTimeUnit: time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// root returns the options the field map was built with, before any were added with with.
+func (o *options) root() *options {
+	if o.base != nil {
+		return o.base
+	}
+	return o
+}
+
+// with returns a copy of o with opts applied. The maps in o are copied too, so the Options can't change o. The fields
+// are already discovered by the time with is called, so it returns an error for an Option that changes which fields
+// are discovered, instead of silently ignoring it.
+func (o *options) with(opts []Option) (*options, error) {
+	if len(opts) == 0 {
+		return o, nil
+	}
+	out := *o
+	out.base = o.root()
+	if o.fieldUnmarshalers != nil {
+		out.fieldUnmarshalers = make(map[string]FieldUnmarshaler, len(o.fieldUnmarshalers))
+		for k, v := range o.fieldUnmarshalers {
+			out.fieldUnmarshalers[k] = v
+		}
+	}
+	if o.fieldTransforms != nil {
+		out.fieldTransforms = make(map[string]func(reflect.Value) error, len(o.fieldTransforms))
+		for k, v := range o.fieldTransforms {
+			out.fieldTransforms[k] = v
+		}
+	}
+	for _, opt := range opts {
+		var probe options
+		opt(&probe)
+		if probe.discoversFields() {
+			return nil, errors.New("Options that change which fields are discovered, such as WithAliases or WithNestedTracking, can only be passed when building")
+		}
+		opt(&out)
+	}
+	return &out, nil
+}
+
+// discoversFields reports whether o sets any of the Options that change which fields are discovered when a field map
+// is built.
+func (o *options) discoversFields() bool {
+	return o.aliases != nil || o.nestedTracking || o.funcResolver != nil || o.keyNormalizer != nil || o.requireModifiable ||
+		o.kebabCase || o.version != 0
+}
+
+// WithMergeIntoExisting decodes JSON objects into the existing value of a struct, map, or non-nil pointer field
+// instead of replacing it with a newly allocated value. Nested fields that are not present in the JSON keep their
+// prior values, which makes it possible to reuse pooled structs or apply partial updates to nested data.
+func WithMergeIntoExisting() Option {
+	return func(o *options) {
+		o.mergeIntoExisting = true
+	}
+}
+
+// WithMaxStringLength rejects any JSON string value longer than n bytes, as measured in the raw (still escaped) JSON
+// input. The check is done before the string is decoded, so oversized values are never allocated. A value of n less than
+// or equal to zero disables the check.
+func WithMaxStringLength(n int) Option {
+	return func(o *options) {
+		o.maxStringLength = n
+	}
+}
+
+// WithAliases registers additional JSON keys for struct fields. The keys of the map are JSON keys and the values are
+// the names of the Go fields they populate. Several JSON keys can refer to the same field; if more than one of them
+// appears in the JSON, the field is populated from each in turn, so the last one wins, and it is only reported once in
+// the modified fields.
+func WithAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		o.aliases = aliases
+	}
+}
+
+// WithNestedTracking reports the modified fields of nested structs instead of only the name of the field containing
+// them. Fields of nested structs are reported as dotted paths (Inner.Address), and values of maps with string keys
+// whose values are structs are reported with the key in brackets (Accounts[key].Balance). If a nested struct implements
+// both json.Unmarshaler and Modifiable, it is unmarshaled with its own UnmarshalJSON method and the fields returned by
+// GetModified are reported. A nested object or map without any fields, or a null value, is reported by the name of the
+// field that contains it.
+func WithNestedTracking() Option {
+	return func(o *options) {
+		o.nestedTracking = true
+	}
+}
+
+// WithUnmarshalerFor populates the named field with fn instead of the built-in unmarshaling logic. The field is
+// identified by its Go name, or by its dotted path when WithNestedTracking is used. If fn returns an error, the field
+// is not reported as modified. WithUnmarshalerFor can be passed more than once to customize several fields. Building
+// the Unmarshaler fails if fieldName doesn't name a field of the struct.
+func WithUnmarshalerFor(fieldName string, fn FieldUnmarshaler) Option {
+	return func(o *options) {
+		if o.fieldUnmarshalers == nil {
+			o.fieldUnmarshalers = map[string]FieldUnmarshaler{}
+		}
+		o.fieldUnmarshalers[fieldName] = fn
+	}
+}
+
+// WithStringlyTyped accepts JSON strings for int, uint, float, and bool fields, parsing the contents of the string into
+// the type of the field. This is meant for APIs that send every value as a string, such as {"age":"37","active":"true"}.
+// Values of the natural JSON type are still accepted.
+func WithStringlyTyped() Option {
+	return func(o *options) {
+		o.stringlyTyped = true
+	}
+}
+
+// WithErrorCounter calls fn with the name of a field each time the field can't be populated from the JSON, for example
+// to increment a metric tracking the fields that clients most often get wrong. With WithNestedTracking, nested fields are
+// identified by their path.
+func WithErrorCounter(fn func(fieldName string)) Option {
+	return func(o *options) {
+		o.errorCounter = fn
+	}
+}
+
+// WithFuncResolver populates fields of function type from JSON strings. The string is looked up in the provided map of
+// names to functions, and the function found is assigned to the field. It is an error if the name isn't in the map or
+// if the function can't be assigned to the field. Without this option, fields of function type are ignored.
+func WithFuncResolver(funcs map[string]interface{}) Option {
+	return func(o *options) {
+		o.funcResolver = funcs
+	}
+}
+
+// WithUnixTimeUnit sets the unit of the Unix timestamps that populate time.Time fields from JSON numbers. By default,
+// timestamps are in seconds; pass time.Millisecond for timestamps in milliseconds. Units smaller than a second must
+// divide a second evenly.
+func WithUnixTimeUnit(unit time.Duration) Option {
+	return func(o *options) {
+		if unit > 0 {
+			o.unixTimeUnit = unit
+		}
+	}
+}
+
+// WithTimeLayout parses the JSON strings that populate time.Time fields with layout, as in time.Parse, instead of
+// requiring RFC 3339.
+func WithTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.timeLayout = layout
+	}
+}
+
+// WithTimeLocation interprets the JSON strings that populate time.Time fields in loc when they don't include a time
+// zone, such as with the layout "2006-01-02 15:04" passed to WithTimeLayout. Times that include a time zone or offset
+// keep it. Without this option, times without a time zone are in UTC.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.timeLocation = loc
+	}
+}
+
+// WithLooseBooleans accepts the JSON numbers 0 and 1 and the JSON strings "true", "false", "yes", "no", "1", and "0"
+// for bool fields, in addition to JSON booleans. Any other number or string is an error.
+func WithLooseBooleans() Option {
+	return func(o *options) {
+		o.looseBooleans = true
+	}
+}
+
+// WithFieldTransform calls fn with the value decoded for the named field from a non-null JSON value, before it is
+// stored, so fn can change the value in place, for example to convert a currency code to upper case. The field is
+// identified like in WithUnmarshalerFor. If fn returns an error, the field keeps its previous value and is not reported
+// as modified. WithFieldTransform can be passed more than once to transform several fields. Building the Unmarshaler
+// fails if fieldName doesn't name a field of the struct, or names a struct or map whose fields are tracked one by one
+// with WithNestedTracking, since such a field isn't stored as a whole.
+func WithFieldTransform(fieldName string, fn func(reflect.Value) error) Option {
+	return func(o *options) {
+		if o.fieldTransforms == nil {
+			o.fieldTransforms = map[string]func(reflect.Value) error{}
+		}
+		o.fieldTransforms[fieldName] = fn
+	}
+}
+
+// WithOnlyFields restricts unmarshaling to the named top-level fields, identified by their Go names. JSON keys for any
+// other field are skipped, or reported as errors with WithRejectOtherFields. It is meant to be passed to
+// Decoder.Decode, so a single Decoder can serve callers that are allowed to set different fields.
+func WithOnlyFields(fieldNames ...string) Option {
+	return func(o *options) {
+		o.onlyFields = make(map[string]bool, len(fieldNames))
+		for _, v := range fieldNames {
+			o.onlyFields[v] = true
+		}
+	}
+}
+
+// WithRejectOtherFields makes JSON keys for fields not listed in WithOnlyFields an error instead of skipping them.
+func WithRejectOtherFields() Option {
+	return func(o *options) {
+		o.rejectOtherFields = true
+	}
+}
+
+// WithUnknownKeyHandler calls fn with each top-level key in the JSON that doesn't match any field. To avoid allocating,
+// the key is passed as bytes that are only valid during the call, so fn must copy them to retain them.
+func WithUnknownKeyHandler(fn func(key []byte)) Option {
+	return func(o *options) {
+		o.unknownKeyHandler = fn
+	}
+}
+
+// WithMaxFields limits the number of modified fields a single JSON document can report, guarding against documents
+// that set huge numbers of fields. With WithNestedTracking, every nested field and map entry counts. Unmarshaling stops
+// with an error as soon as the limit is exceeded.
+func WithMaxFields(n int) Option {
+	return func(o *options) {
+		o.maxFields = n
+	}
+}
+
+// progressInterval is the number of fields between calls to the function passed to WithProgress.
+const progressInterval = 100
+
+// WithProgress calls fn with the number of fields processed so far every 100 fields while unmarshaling, and with the
+// total when unmarshaling ends, if it wasn't just reported. With WithNestedTracking, nested fields and the fields that
+// contain them all count. This is meant to show progress while unmarshaling very large documents.
+func WithProgress(fn func(fieldsProcessed int)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// WithInterfaceResolver populates fields of interface type or of pointer to interface type, which can't be unmarshaled
+// without knowing the concrete type to create. For every non-null JSON value of such a field, fn is called with the
+// name of the field, identified like in WithUnmarshalerFor, and the raw JSON of the value. It returns the value to
+// assign to the field, typically after looking at a discriminator in the JSON to choose the concrete type and
+// unmarshaling the JSON into it. For a pointer to an interface, a new pointer is allocated to hold the value.
+func WithInterfaceResolver(fn func(fieldName string, raw []byte) (reflect.Value, error)) Option {
+	return func(o *options) {
+		o.interfaceResolver = fn
+	}
+}
+
+// WithNumberParser populates int, uint, and float fields from JSON strings using fn, for example to parse numbers
+// formatted for a locale, such as "1.234,56". fn is called with the unescaped contents of the string and the kind of
+// the field, and returns a value that is converted to the type of the field. JSON numbers are still parsed the
+// standard way. WithNumberParser takes precedence over WithStringlyTyped and the string option of the json tag.
+func WithNumberParser(fn func(raw []byte, kind reflect.Kind) (reflect.Value, error)) Option {
+	return func(o *options) {
+		o.numberParser = fn
+	}
+}
+
+// WithRejectTrailingData makes it an error for anything but whitespace to follow the JSON object, like
+// json.Unmarshal does. By default, anything after the end of the object is ignored.
+func WithRejectTrailingData() Option {
+	return func(o *options) {
+		o.rejectTrailingData = true
+	}
+}
+
+// WithDeprecationHandler calls fn with the name of each field tagged modtracker:"deprecated" that has a value in the
+// JSON, so clients still sending deprecated fields can be tracked. The field is populated as usual. Nested fields are
+// identified like in WithUnmarshalerFor. fn is called once for every key that matches the field.
+func WithDeprecationHandler(fn func(fieldName string)) Option {
+	return func(o *options) {
+		o.deprecationHandler = fn
+	}
+}
+
+// WithCoerceNumbersToStrings accepts JSON numbers for string fields, storing the number as it appears in the JSON, so
+// {"zip":90210} populates a string field with "90210". Since this can hide mistakes by clients, it is off by default.
+func WithCoerceNumbersToStrings() Option {
+	return func(o *options) {
+		o.coerceNumbers = true
+	}
+}
+
+// WithFieldValidator calls fn for every field populated from the JSON, including fields set to null, with the name of
+// the field, identified like in WithUnmarshalerFor, the value for the field, and the fields modified before it. fn is
+// called before the value is stored, so if fn returns an error, the field keeps its previous value and is not reported
+// as modified. Since fields are populated in the order their keys appear in the JSON, checks that involve several
+// fields are more reliable in PostDecode; modifiedSoFar is meant for the checks where the fields that came first are
+// enough.
+func WithFieldValidator(fn func(fieldName string, v reflect.Value, modifiedSoFar []string) error) Option {
+	return func(o *options) {
+		o.fieldValidator = fn
+	}
+}
+
+// WithTrimStrings removes leading and trailing white space from the JSON strings that populate string fields. Fields
+// tagged modtracker:"notrim", such as passwords, are populated with the string as is.
+func WithTrimStrings() Option {
+	return func(o *options) {
+		o.trimStrings = true
+	}
+}
+
+// WithEmptyObjectAsNull treats an empty JSON object for a pointer to a struct or map like null, setting the field to
+// nil instead of a pointer to a zero value. Either way, the field is listed in Result.Empty. An empty object for a
+// pointer to any other type is still an error.
+func WithEmptyObjectAsNull() Option {
+	return func(o *options) {
+		o.emptyObjectAsNull = true
+	}
+}
+
+// WithRootPath populates the struct from the JSON object found by following the keys in path, instead of the
+// top-level object, for payloads wrapped in an envelope such as {"data":{...}}. The modified fields are reported
+// relative to that object. It is an error if there is no object at the path.
+func WithRootPath(path ...string) Option {
+	return func(o *options) {
+		o.rootPath = path
+	}
+}
+
+// WithIgnoreZeroValues ignores the JSON values that are the zero value of their field, such as 0, "", or false, for
+// clients that send every field and mean only the non-zero ones as changes: the fields keep their previous values and
+// are left out of Result.Modified. A type with an IsZero() bool method, like time.Time, decides for itself what its
+// zero value is. Fields set to null are still reported, and pointer fields are reported whenever they are set to a
+// value.
+func WithIgnoreZeroValues() Option {
+	return func(o *options) {
+		o.ignoreZeroValues = true
+	}
+}
+
+// WithKeyNormalizer matches the keys in the JSON to fields after passing both through fn, so that a key matches a
+// field when fn returns the same string for the key and for the field's JSON key. fn applies to the JSON keys of the
+// fields whether they come from the json tag, the field name, a modtracker:"alias" tag, or WithAliases, so
+// func(k string) string { return strings.ToLower(strings.Replace(k, "_", "", -1)) } matches user_name, userName, and
+// UserName to a field with the JSON key userName. It is an error if fn returns the same string for the JSON keys of
+// two fields. Like WithAliases, WithKeyNormalizer is an error when passed to Decoder.Decode.
+func WithKeyNormalizer(fn func(jsonKey string) string) Option {
+	return func(o *options) {
+		o.keyNormalizer = fn
+	}
+}
+
+// WithRequireModifiable makes building an Unmarshaler or a Decoder fail if the struct type doesn't implement
+// Modifiable, either directly or through a pointer, to catch a forgotten GetModified method at startup.
+func WithRequireModifiable() Option {
+	return func(o *options) {
+		o.requireModifiable = true
+	}
+}
+
+// WithMaxSliceLength sets the maximum number of elements in a JSON array that populates a slice or array field. It is
+// an error if an array has more elements, or, with WithSliceAppend, if the slice has more elements once the array is
+// appended to it. A field tagged modtracker:"maxlen=n" uses its own maximum instead. A value less than or equal to zero
+// disables the check.
+//
+// Only the array that populates the field itself is checked. The arrays nested in it, such as the inner arrays of a
+// [][]int field, the arrays in the values of a map field, and the arrays inside a struct or a type that is unmarshaled
+// by encoding/json can have any number of elements.
+func WithMaxSliceLength(n int) Option {
+	return func(o *options) {
+		o.maxSliceLength = n
+	}
+}
+
+// WithDisallowUnknownFields makes it an error for the JSON to contain a key that doesn't match any field, like
+// json.Decoder.DisallowUnknownFields. Each unknown key is reported with its path, such as Inner.color for the key color
+// in the object for the field Inner. Keys are checked in the objects for nested structs only when WithNestedTracking is
+// used; objects that are unmarshaled as a whole by encoding/json, including structs that report their own modified
+// fields through Modifiable, can't be inspected. A struct with a field tagged modtracker:"extras" collects its unknown
+// keys instead.
+func WithDisallowUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknown = true
+	}
+}
+
+// WithLenientNumberSyntax accepts numbers with a plus sign or leading zeros, such as +5 or 05, which JSON doesn't allow
+// but some producers emit. Without this option, a leading zero in a number for an int, uint, or float field is an
+// error, and a plus sign makes the JSON invalid. Fields tagged raw or extras, and Result.Changes, still hold the JSON
+// as it was sent, plus signs included.
+func WithLenientNumberSyntax() Option {
+	return func(o *options) {
+		o.lenientNumbers = true
+	}
+}
+
+// WithValueInterceptor calls fn for every field found in the JSON before the field is populated, with the name of the
+// field, identified like in WithUnmarshalerFor, the type of the JSON value, and its raw bytes, which for JSON strings
+// are the still escaped contents without the surrounding quotes. If fn returns true, the field is left to fn, which is
+// expected to have populated it, for example through a pointer to the struct captured when fn was created, and the
+// field is reported as modified. If fn returns false, the field is populated as usual. If fn returns an error, the field
+// is not reported as modified.
+func WithValueInterceptor(fn func(fieldName string, vt jsonparser.ValueType, raw []byte) (handled bool, err error)) Option {
+	return func(o *options) {
+		o.valueInterceptor = fn
+	}
+}
+
+// WithDuplicateKeyHandler calls fn for every field whose JSON key, or one of its aliases, appears more than once in the
+// same JSON object, with the name of the field, identified like in WithUnmarshalerFor, and the number of times it
+// appears. The option only reports the keys: with or without it, the field is populated from the last of the values,
+// like encoding/json does.
+func WithDuplicateKeyHandler(fn func(fieldName string, count int)) Option {
+	return func(o *options) {
+		o.duplicateKeys = fn
+	}
+}
+
+// WithSliceAppend appends the elements of a JSON array to the current value of a slice field instead of replacing it,
+// to accumulate the items of a list over several partial updates. null still sets the field to nil. Pointers to slices
+// are replaced as usual.
+func WithSliceAppend() Option {
+	return func(o *options) {
+		o.sliceAppend = true
+	}
+}
+
+// WithKebabCase matches fields without a JSON key in their json tag to the kebab-case form of their name, such as
+// first-name for FirstName. Acronyms are kept together, so UserID becomes user-id and HTTPServer becomes http-server.
+// Fields whose json tag sets a key keep that key.
+func WithKebabCase() Option {
+	return func(o *options) {
+		o.kebabCase = true
+	}
+}
+
+// WithVersion selects the version of the API that JSON is decoded for. A field tagged with a key for that version, such
+// as `modtracker:"v1=name,v2=fullName"`, is matched by that key instead of the one from its json tag or name. Fields
+// without a key for the version keep their usual key. The fields that are modified are still reported by their names.
+// Versions start at 1.
+func WithVersion(v int) Option {
+	return func(o *options) {
+		o.version = v
+	}
+}
+
+// WithPartialResults returns the fields that were modified along with the error when decoding fails, instead of nil.
+// Fields whose values could not be decoded, or were rejected by a min or max tag, WithFieldTransform, or
+// WithFieldValidator, keep their previous values and are left out of every list in the Result, so callers can apply the
+// fields that were populated and report the ones that weren't. With WithNestedTracking, a struct or map is rejected as
+// a whole if any of its nested fields is. Errors that stop decoding before any field is looked at, such as a target of
+// the wrong type, still return no fields.
+func WithPartialResults() Option {
+	return func(o *options) {
+		o.partialResults = true
+	}
+}
@@ -0,0 +1,121 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModifiedOrder determines the order of the modified fields returned by an Unmarshaler.
+type ModifiedOrder int
+
+const (
+	// DocumentOrder lists the modified fields in the order their keys appear in the JSON. This is the default.
+	DocumentOrder ModifiedOrder = iota
+	// DeclarationOrder lists the modified fields in the order they are declared in the struct, so the result doesn't
+	// depend on how the client ordered the keys. Nested fields reported by WithNestedTracking are grouped under the
+	// field that contains them, in the order they are declared in their own struct. Map entries stay in document
+	// order, as do the fields reported by nested structs that implement Modifiable.
+	DeclarationOrder
+)
+
+// WithModifiedOrder sets the order of the modified fields returned by the Unmarshaler. The order applies to every list
+// of fields in a Result.
+func WithModifiedOrder(order ModifiedOrder) Option {
+	return func(o *options) {
+		o.modifiedOrder = order
+	}
+}
+
+// A location identifies where a field recorded in a Result is in the struct and in the JSON. rank holds the position in
+// its struct of the field and of each field containing it, or, for map entries, the position of the entry in the JSON
+// object. pointer is the JSON Pointer of the field.
+type location struct {
+	rank    []int
+	pointer string
+}
+
+// locate records the location of the field n, found at position i and under the JSON key key below the field being
+// decoded, and returns it.
+func (d *decodeState) locate(n string, i int, key string) location {
+	l := location{
+		rank:    append(append(make([]int, 0, len(d.loc.rank)+1), d.loc.rank...), i),
+		pointer: d.loc.pointer + "/" + escapeJSONPointer(key),
+	}
+	d.locs[n] = l
+	return l
+}
+
+// sortDeclarationOrder sorts the fields in each slice by their locations, so fields come in the order they are
+// declared, and the fields nested in a field come right after it.
+func sortDeclarationOrder(locs map[string]location, fields ...[]string) {
+	for _, f := range fields {
+		sort.SliceStable(f, func(i, j int) bool {
+			return lessRank(locs[f[i]].rank, locs[f[j]].rank)
+		})
+	}
+}
+
+// lessRank reports whether the location ranked a comes before the one ranked b. A field comes before the fields
+// nested in it.
+func lessRank(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// topLevelField returns the name of the top-level field in a modified field path such as Inner.Address or
+// Accounts[key].Balance.
+func topLevelField(path string) string {
+	if idx := strings.IndexAny(path, ".["); idx != -1 {
+		return path[:idx]
+	}
+	return path
+}
+
+// MergeModified returns the union of the lists of modified fields, such as those returned by several partial decodes
+// of the same struct, without duplicates. The fields are ordered by their first appearance, except that the fields
+// nested in a top-level field, like Inner.A and Inner.B, are kept together with that field. Merging lists in document
+// order or in declaration order gives a list in the same order, as long as the lists agree on the order of the fields
+// they share.
+func MergeModified(slices ...[]string) []string {
+	seen := map[string]bool{}
+	groups := map[string][]string{}
+	var tops []string
+	for _, s := range slices {
+		for _, v := range s {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			top := topLevelField(v)
+			if _, ok := groups[top]; !ok {
+				tops = append(tops, top)
+			}
+			groups[top] = append(groups[top], v)
+		}
+	}
+	var out []string
+	for _, top := range tops {
+		out = append(out, groups[top]...)
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"strings"
+)
+
+// WithJSONPointerPaths reports the modified fields as JSON Pointers (RFC 6901) built from the JSON keys instead of the
+// Go field names, such as /firstName or, with WithNestedTracking, /inner/address and /accounts/key/balance. The
+// fields of nested structs that report their own modified fields through Modifiable keep their Go names. The option
+// applies to every list of fields in a Result, while field names in errors and in other Options stay Go names.
+func WithJSONPointerPaths() Option {
+	return func(o *options) {
+		o.jsonPointerPaths = true
+	}
+}
+
+// convertJSONPointers replaces each field path in the slices with the JSON Pointer recorded for it while decoding.
+func convertJSONPointers(locs map[string]location, fields ...[]string) {
+	for _, f := range fields {
+		for i, v := range f {
+			f[i] = locs[v].pointer
+		}
+	}
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapeJSONPointer escapes a JSON key for use as a JSON Pointer reference token.
+func escapeJSONPointer(key string) string {
+	return jsonPointerEscaper.Replace(key)
+}
@@ -0,0 +1,167 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"fmt"
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"reflect"
+	"sync"
+)
+
+// polymorphics holds the types registered with RegisterPolymorphic, as a *polymorphic for each interface type.
+var polymorphics sync.Map
+
+type polymorphic struct {
+	key   string
+	types map[string]reflect.Type
+}
+
+// polymorphicMap identifies the field map of a concrete type registered with RegisterPolymorphic.
+type polymorphicMap struct {
+	p *polymorphic
+	t reflect.Type
+}
+
+// RegisterPolymorphic registers the concrete types of ifaceType, an interface type, so that fields of type ifaceType,
+// or of pointer to ifaceType, can be populated from a JSON object whose discriminatorKey holds a string naming the
+// type, such as {"kind": "circle", "radius": 2}. mapping maps each name to a struct type, or a pointer to a struct
+// type, that implements ifaceType. The object is decoded into a new value of the named type with the Options of the
+// Unmarshaler, like a nested struct. The field is reported as modified, or, with WithNestedTracking, the fields of the
+// concrete type are. The discriminator key is never reported as an unknown field. It is an error if the discriminator
+// is missing or names an unregistered type.
+//
+// A resolver passed to WithInterfaceResolver takes precedence over the registered types. Registering ifaceType again
+// replaces its types. RegisterPolymorphic is safe to call concurrently with unmarshaling, and panics if ifaceType
+// isn't an interface type or a mapped type can't be decoded into or doesn't implement ifaceType.
+func RegisterPolymorphic(ifaceType reflect.Type, discriminatorKey string, mapping map[string]reflect.Type) {
+	if ifaceType.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("modtracker: RegisterPolymorphic called with non-interface type %s", ifaceType))
+	}
+	p := &polymorphic{
+		key:   discriminatorKey,
+		types: make(map[string]reflect.Type, len(mapping)),
+	}
+	for name, t := range mapping {
+		st := t
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		if st.Kind() != reflect.Struct || !t.Implements(ifaceType) {
+			panic(fmt.Sprintf("modtracker: RegisterPolymorphic called with type %s for %s, which isn't a struct implementing %s", t, name, ifaceType))
+		}
+		if _, err := p.fieldMap(nil, defaultOptions, st); err != nil {
+			panic(fmt.Sprintf("modtracker: RegisterPolymorphic called with type %s for %s: %v", t, name, err))
+		}
+		p.types[name] = t
+	}
+	polymorphics.Store(ifaceType, p)
+}
+
+// polymorphicFor returns the types registered for t with RegisterPolymorphic, or nil if there are none.
+func polymorphicFor(t reflect.Type) *polymorphic {
+	if p, ok := polymorphics.Load(t); ok {
+		return p.(*polymorphic)
+	}
+	return nil
+}
+
+// fieldMap returns the field map of st built with o, building it the first time it is requested and keeping it in
+// cache, which belongs to the field map of the Unmarshaler built with o, if cache isn't nil. The discriminator key is
+// treated as one of the keys of st, so it isn't reported as unknown or kept in a field tagged extras.
+func (p *polymorphic) fieldMap(cache *sync.Map, o *options, st reflect.Type) (*fieldMap, error) {
+	key := polymorphicMap{p: p, t: st}
+	if cache != nil {
+		if fm, ok := cache.Load(key); ok {
+			return fm.(*fieldMap), nil
+		}
+	}
+	fm, err := buildJSONFieldMapForType(st, o)
+	if err != nil {
+		return nil, err
+	}
+	fm.known[fm.normalize([]byte(p.key))] = true
+	if cache == nil {
+		return &fm, nil
+	}
+	actual, _ := cache.LoadOrStore(key, &fm)
+	return actual.(*fieldMap), nil
+}
+
+// polymorphic populates an interface field, or a pointer to an interface, with a new value of the type named by the
+// discriminator in the JSON object in value.
+func (d *decodeState) polymorphic(p *polymorphic, fValue *fieldValue, value []byte, fv reflect.Value, target reflect.Value, n string, first bool) bool {
+	name, err := jsonparser.GetString(value, p.key)
+	if err != nil {
+		d.fail(n, errors.Errorf("Invalid value in JSON, expected a string %s in the object for field %s", p.key, n))
+		return false
+	}
+	t, ok := p.types[name]
+	if !ok {
+		d.fail(n, errors.Errorf("Invalid value in JSON, unknown %s %q for field %s", p.key, name, n))
+		return false
+	}
+	st := t
+	if t.Kind() == reflect.Ptr {
+		st = t.Elem()
+	}
+	//the field map is built with the options of the Unmarshaler, not those added for a single call
+	fm, err := p.fieldMap(d.concrete, d.o.root(), st)
+	if err != nil {
+		d.fail(n, wrapErrors(err, "Field %s", n))
+		return false
+	}
+	cv := reflect.New(st)
+	mark := d.mark()
+	tracked := d.o.nestedTracking && first
+	if !tracked {
+		//the fields of the concrete type aren't reported, so they don't count against WithMaxFields either
+		d.freeze()
+	}
+	errs := len(d.el)
+	d.object(fm, value, cv.Elem(), n+".")
+	if !tracked {
+		d.reset(mark)
+	}
+	if len(d.el) > errs {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		fv.Elem().Set(cv)
+	} else {
+		fv.Elem().Set(cv.Elem())
+	}
+	if !d.o.nestedTracking {
+		return d.assign(fValue, fv, jsonparser.Object, target, n, first)
+	}
+	if fValue.kind == reflect.Ptr {
+		if d.detailed && first {
+			//the pointer goes ahead of the fields of the concrete type, like it would had it been recorded first
+			d.res.SetNonNull = append(d.res.SetNonNull, "")
+			copy(d.res.SetNonNull[mark.setNonNull+1:], d.res.SetNonNull[mark.setNonNull:])
+			d.res.SetNonNull[mark.setNonNull] = n
+		}
+		target.Set(fv)
+	} else {
+		target.Set(fv.Elem())
+	}
+	if tracked && len(d.res.Modified) == mark.modified {
+		d.res.Modified = append(d.res.Modified, n)
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"encoding/json"
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// A Result describes the outcome of unmarshaling in more detail than the modified field slice returned by an
+// Unmarshaler.
+type Result struct {
+	// Modified contains the fields that were populated from the JSON, including the fields that were set to null.
+	// It is identical to the slice returned by an Unmarshaler.
+	Modified []string
+	// Nulled contains the fields that were explicitly set to null in the JSON.
+	Nulled []string
+	// Cleared contains the fields in Nulled that had a non-nil value before unmarshaling.
+	Cleared []string
+	// Empty contains the fields that were set to an empty JSON object. With WithEmptyObjectAsNull, the pointers to
+	// structs and maps among them are in Nulled too.
+	Empty []string
+	// SetNonNull contains the pointer fields that were set to a value other than null, such as the optional fields
+	// that were filled in on a form. A pointer to a nested struct comes before its own fields, and, with
+	// WithNestedTracking, it is listed here even when Modified only holds the nested fields that were set.
+	SetNonNull []string
+	// Changes contains the JSON value of each field in Modified that was populated from a single JSON value, exactly as
+	// it appears in the input, in the same order as Modified and with the same field names. Only a DetailedUnmarshaler
+	// fills in Changes.
+	Changes []Change
+}
+
+// A Change holds the JSON value that populated a field. Raw is the unparsed text of the value, so numbers keep the
+// digits that were sent, such as 37.50 for a float64 field holding 37.5, and strings keep their quotes and escapes.
+type Change struct {
+	Field string
+	Raw   json.RawMessage
+}
+
+// change records the JSON value of the field n in d.res.Changes. For strings, value holds the contents without the
+// surrounding quotes.
+func (d *decodeState) change(n string, value []byte, vt jsonparser.ValueType) {
+	value = d.source(value)
+	raw := make(json.RawMessage, 0, len(value)+2)
+	if vt == jsonparser.String {
+		raw = append(append(append(raw, '"'), value...), '"')
+	} else {
+		raw = append(raw, value...)
+	}
+	d.res.Changes = append(d.res.Changes, Change{Field: n, Raw: raw})
+}
+
+// ModifiedByField groups the modified fields under the top-level field that contains them, such as
+// {"Inner": ["Address", "Zip"]} for Inner.Address and Inner.Zip reported by WithNestedTracking. The paths in the groups
+// are relative to the top-level field, so map entries keep their brackets, such as [key].Balance. A top-level field
+// that was modified as a whole maps to an empty group.
+func (r Result) ModifiedByField() map[string][]string {
+	out := make(map[string][]string, len(r.Modified))
+	for _, v := range r.Modified {
+		top := topLevelField(v)
+		group := out[top]
+		if rest := strings.TrimPrefix(v[len(top):], "."); rest != "" {
+			group = append(group, rest)
+		} else if group == nil {
+			group = []string{}
+		}
+		out[top] = group
+	}
+	return out
+}
+
+// A DetailedUnmarshaler works like an Unmarshaler, but returns a Result instead of only the modified fields. In case
+// of error, the struct might be partially populated and the returned Result will be empty, unless WithPartialResults
+// is used.
+type DetailedUnmarshaler func([]byte, interface{}) (Result, error)
+
+// UnmarshalJSONDetailed provides the default implementation of the DetailedUnmarshaler type. Like UnmarshalJSON, it
+// caches the fields in the structure the first time a struct type is passed in.
+func UnmarshalJSONDetailed(data []byte, s interface{}) (Result, error) {
+	fm, err := cachedJSONFieldMap(s)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "Failure during UnmarshalJSON")
+	}
+
+	return decodeJSON(fm, &decodeState{o: defaultOptions, detailed: true}, data, s)
+}
+
+// BuildDetailedJSONUnmarshaler works like BuildJSONUnmarshaler, but generates a DetailedUnmarshaler.
+func BuildDetailedJSONUnmarshaler(s interface{}, opts ...Option) (DetailedUnmarshaler, error) {
+	o := buildOptions(opts)
+	fm, err := buildJSONFieldMap(s, o)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failure during UnmarshalJSON")
+	}
+
+	return func(data []byte, s interface{}) (Result, error) {
+		return decodeJSON(fm, &decodeState{o: o, detailed: true}, data, s)
+	}, nil
+}
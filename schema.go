@@ -0,0 +1,198 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"encoding/json"
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonSchema is the subset of a JSON Schema understood by BuildUnmarshalerFromSchema.
+type jsonSchema struct {
+	Type                 schemaTypes           `json:"type"`
+	Properties           map[string]jsonSchema `json:"properties"`
+	Required             []string              `json:"required"`
+	AdditionalProperties *bool                 `json:"additionalProperties"`
+}
+
+// schemaTypes holds the types allowed by the type keyword of a JSON Schema, which is either a single type or a list.
+type schemaTypes []string
+
+func (st *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*st = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("type must be a string or an array of strings")
+	}
+	*st = many
+	return nil
+}
+
+// allows reports whether the JSON value in value, of type vt, has one of the types in st. An empty st allows any type.
+func (st schemaTypes) allows(value []byte, vt jsonparser.ValueType) bool {
+	if len(st) == 0 {
+		return true
+	}
+	for _, t := range st {
+		switch {
+		case t == "string" && vt == jsonparser.String,
+			t == "number" && vt == jsonparser.Number,
+			t == "boolean" && vt == jsonparser.Boolean,
+			t == "object" && vt == jsonparser.Object,
+			t == "array" && vt == jsonparser.Array,
+			t == "null" && vt == jsonparser.Null:
+			return true
+		case t == "integer" && vt == jsonparser.Number:
+			f, err := strconv.ParseFloat(string(value), 64)
+			if err == nil && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var schemaTypeNames = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true, "object": true, "array": true, "null": true,
+}
+
+// BuildUnmarshalerFromSchema builds an Unmarshaler from a JSON Schema instead of a struct, for payloads that have a
+// schema but no Go type. The Unmarshaler populates a map[string]interface{}, passed in directly or as a pointer, with
+// the top-level properties of a JSON object and returns the properties that were present, in the order they appear in
+// the JSON. Values are converted like encoding/json does for an interface{}.
+//
+// Only the type, properties, required, and additionalProperties keywords of the top-level schema are enforced, and
+// only the types of the top-level properties are checked. If the JSON doesn't match the schema, the map is left
+// untouched and every problem found is returned.
+func BuildUnmarshalerFromSchema(schema []byte) (Unmarshaler, error) {
+	var js jsonSchema
+	if err := json.Unmarshal(schema, &js); err != nil {
+		return nil, errors.Wrap(err, "Invalid JSON Schema")
+	}
+	if len(js.Type) > 0 && !(len(js.Type) == 1 && js.Type[0] == "object") {
+		return nil, errors.Errorf("Invalid JSON Schema, expected type object, got %s", strings.Join(js.Type, ", "))
+	}
+	names := make([]string, 0, len(js.Properties))
+	for k := range js.Properties {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		for _, t := range js.Properties[k].Type {
+			if !schemaTypeNames[t] {
+				return nil, errors.Errorf("Invalid JSON Schema, unknown type %s for property %s", t, k)
+			}
+		}
+	}
+	return func(data []byte, target interface{}) ([]string, error) {
+		var m map[string]interface{}
+		switch t := target.(type) {
+		case map[string]interface{}:
+			m = t
+		case *map[string]interface{}:
+			if t != nil {
+				if *t == nil {
+					*t = map[string]interface{}{}
+				}
+				m = *t
+			}
+		}
+		if m == nil {
+			return nil, errors.Errorf("Failure during UnmarshalJSON: target must be a non-nil map[string]interface{}, got %T", target)
+		}
+		return js.decode(data, m)
+	}, nil
+}
+
+// decode populates m with the top-level properties of the JSON object in data if they match js.
+func (js *jsonSchema) decode(data []byte, m map[string]interface{}) ([]string, error) {
+	value, vt, _, err := jsonparser.Get(trimBOM(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid JSON")
+	}
+	if vt != jsonparser.Object {
+		return nil, errors.Errorf("Invalid JSON, expected an object, got %s", vt)
+	}
+	values := map[string]interface{}{}
+	seen := map[string]bool{}
+	var present []string
+	var el errorList
+	err = jsonparser.ObjectEach(value, func(key []byte, value []byte, vt jsonparser.ValueType, _ int) error {
+		k := string(key)
+		seen[k] = true
+		prop, ok := js.Properties[k]
+		if !ok && js.AdditionalProperties != nil && !*js.AdditionalProperties {
+			el = append(el, errors.Errorf("Property %s is not allowed by the schema", k))
+			return nil
+		}
+		if !prop.Type.allows(value, vt) {
+			el = append(el, errors.Errorf("Invalid type in JSON, expected %s for property %s, got %s", strings.Join(prop.Type, " or "), k, vt))
+			return nil
+		}
+		v, err := schemaValue(value, vt)
+		if err != nil {
+			el = append(el, errors.Wrapf(err, "Invalid JSON for property %s", k))
+			return nil
+		}
+		if _, ok := values[k]; !ok {
+			present = append(present, k)
+		}
+		values[k] = v
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid JSON")
+	}
+	for _, k := range js.Required {
+		if !seen[k] {
+			el = append(el, errors.Errorf("Required property %s is missing from JSON", k))
+		}
+	}
+	if el != nil {
+		return nil, el
+	}
+	for k, v := range values {
+		m[k] = v
+	}
+	return present, nil
+}
+
+// schemaValue converts a JSON value like encoding/json does for an interface{}.
+func schemaValue(value []byte, vt jsonparser.ValueType) (interface{}, error) {
+	switch vt {
+	case jsonparser.String:
+		return jsonparser.ParseString(value)
+	case jsonparser.Number:
+		return strconv.ParseFloat(string(value), 64)
+	case jsonparser.Boolean:
+		return string(value) == "true", nil
+	case jsonparser.Null:
+		return nil, nil
+	}
+	var v interface{}
+	err := json.Unmarshal(value, &v)
+	return v, err
+}
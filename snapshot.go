@@ -0,0 +1,42 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DecodeWithSnapshot works like UnmarshalJSON, but also returns the values the modified fields had before they were
+// populated from the JSON, keyed by field name, so callers can record changes such as "changed X from A to B". The
+// values are shallow copies: the previous value of a pointer, slice, or map field refers to the same data it did
+// before decoding. If there is an error, before is nil.
+func DecodeWithSnapshot(data []byte, s interface{}) (before map[string]interface{}, modified []string, err error) {
+	fm, err := cachedJSONFieldMap(s)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failure during UnmarshalJSON")
+	}
+
+	d := decodeState{
+		o:        defaultOptions,
+		snapshot: map[string]interface{}{},
+	}
+	res, err := decodeJSON(fm, &d, data, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.snapshot, res.Modified, nil
+}
@@ -0,0 +1,42 @@
+//Copyright 2016 Capital One Services, LLC
+//
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-Copyright: Copyright (c) Capital One Services, LLC
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and limitations under the License.
+
+package modtracker
+
+import (
+	"time"
+)
+
+// Stats describes a single call to an Unmarshaler, for instrumentation.
+type Stats struct {
+	// BytesIn is the length of the JSON.
+	BytesIn int
+	// FieldsPresent is the number of top-level fields with a value in the JSON, whether or not the value could be
+	// assigned. A field matched by more than one key is counted once.
+	FieldsPresent int
+	// FieldsRegistered is the number of top-level fields that can be populated from the JSON.
+	FieldsRegistered int
+	// Duration is the time spent unmarshaling, including the time spent in PostDecode.
+	Duration time.Duration
+}
+
+// WithStats calls fn with the Stats of each call to the Unmarshaler, after unmarshaling has finished, even if it
+// failed. fn is called synchronously, so it should be fast, for example only recording the Stats in a metrics library.
+func WithStats(fn func(Stats)) Option {
+	return func(o *options) {
+		o.stats = fn
+	}
+}